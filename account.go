@@ -0,0 +1,99 @@
+package unifi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Account is a controller administrator.
+type Account struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"email"`
+	IsSuperAdmin bool   `json:"is_super"`
+
+	Sites []Site `json:"-"` // the sites the account can access, with its role on each
+
+	// TODO: other fields
+}
+
+// CurrentUser returns the account that the API is authenticated as.
+func (api *API) CurrentUser() (Account, error) {
+	var resp []Account
+	if err := api.get("/api/self", &resp, reqOpts{}); err != nil {
+		return Account{}, err
+	}
+	if len(resp) == 0 {
+		return Account{}, fmt.Errorf("controller did not report the current user")
+	}
+	acct := resp[0]
+	if err := api.get("/api/self/sites", &acct.Sites, reqOpts{}); err != nil {
+		return Account{}, err
+	}
+	return acct, nil
+}
+
+// ChangePassword changes the password of the account that the API is authenticated as.
+// On success the API uses the new password for future logins, and saves it
+// to the AuthStore along with the current session (as by WriteConfig).
+// An AuthStore wrapped by TransientPassword doesn't store passwords,
+// so in that case the caller must arrange to supply the new password in future.
+func (api *API) ChangePassword(currentPassword, newPassword string) error {
+	req := struct {
+		OldPassword string `json:"x_old_password"`
+		NewPassword string `json:"x_password"`
+	}{currentPassword, newPassword}
+	if err := api.put("/api/self", &req, &json.RawMessage{}, reqOpts{}); err != nil {
+		return err
+	}
+	api.mu.Lock()
+	api.password = newPassword
+	api.mu.Unlock()
+	if err := api.WriteConfig(); err != nil {
+		return fmt.Errorf("password changed, but saving it failed: %w", err)
+	}
+	return nil
+}
+
+// Admin is an administrator of a site.
+type Admin struct {
+	ID           string `json:"_id"`
+	Name         string `json:"name"`
+	EmailAddress string `json:"email"`
+	Role         string `json:"role"` // e.g. "admin", "readonly"
+	IsSuperAdmin bool   `json:"is_super"`
+
+	// TODO: other fields
+}
+
+// ListAdmins returns the administrators of a site.
+func (api *API) ListAdmins(site string) ([]Admin, error) {
+	req := struct {
+		Cmd string `json:"cmd"`
+	}{"get-admins"}
+	var resp []Admin
+	if err := api.post("/api/s/"+site+"/cmd/sitemgr", &req, &resp, reqOpts{safe: true}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// InviteAdmin invites someone by email to administer a site with the given role
+// (e.g. "admin", "readonly").
+func (api *API) InviteAdmin(site, name, email, role string) error {
+	req := struct {
+		Cmd   string `json:"cmd"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}{"invite-admin", name, email, role}
+	return api.sitemgr(site, &req, &json.RawMessage{})
+}
+
+// RemoveAdmin revokes an administrator's access to a site.
+func (api *API) RemoveAdmin(site, adminID string) error {
+	req := struct {
+		Cmd   string `json:"cmd"`
+		Admin string `json:"admin"`
+	}{"revoke-admin", adminID}
+	return api.sitemgr(site, &req, &json.RawMessage{})
+}
@@ -0,0 +1,328 @@
+package unifi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dsymonds/unifi"
+	"github.com/dsymonds/unifi/unifitest"
+)
+
+// Run with -race.
+func TestConcurrentControllers(t *testing.T) {
+	var apis []*unifi.API
+	for i := 0; i < 2; i++ {
+		s := unifitest.NewServer()
+		defer s.Close()
+		s.Handle("/api/s/default/stat/sta", []interface{}{
+			map[string]interface{}{"mac": fmt.Sprintf("00:00:00:00:00:%02x", i)},
+		})
+		api, err := s.API(nil)
+		if err != nil {
+			t.Fatalf("NewAPI: %v", err)
+		}
+		apis = append(apis, api)
+	}
+
+	var wg sync.WaitGroup
+	for i, api := range apis {
+		want := fmt.Sprintf("00:00:00:00:00:%02x", i)
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func(api *unifi.API) {
+				defer wg.Done()
+				clients, err := api.ListClients("default")
+				if err != nil {
+					t.Errorf("ListClients: %v", err)
+					return
+				}
+				if len(clients) != 1 || clients[0].MAC != want {
+					t.Errorf("ListClients = %+v, want one client with MAC %s", clients, want)
+				}
+			}(api)
+		}
+		// Changing the password while the session is being saved must be safe too.
+		done := make(chan struct{})
+		wg.Add(2)
+		go func(api *unifi.API) {
+			defer wg.Done()
+			defer close(done)
+			for j := 0; j < 10; j++ {
+				if err := api.ChangePassword("password", "password"); err != nil {
+					t.Errorf("ChangePassword: %v", err)
+				}
+			}
+		}(api)
+		go func(api *unifi.API) {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if err := api.WriteConfig(); err != nil {
+					t.Errorf("WriteConfig: %v", err)
+					return
+				}
+			}
+		}(api)
+	}
+	wg.Wait()
+}
+
+// TestConcurrentLogin checks that concurrent requests needing a session
+// share a single login, and are sent with a matching CSRF token.
+func TestConcurrentLogin(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		logins int
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/login" {
+			logins++
+			session := strconv.Itoa(logins)
+			http.SetCookie(w, &http.Cookie{Name: "unifises", Value: session, Path: "/"})
+			w.Header().Set("X-CSRF-Token", "token"+session)
+			io.WriteString(w, `{"meta":{"rc":"ok"},"data":[]}`)
+			return
+		}
+		c, err := r.Cookie("unifises")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"meta":{"rc":"error","msg":"api.err.LoginRequired"},"data":[]}`)
+			return
+		}
+		if r.Header.Get("X-CSRF-Token") != "token"+c.Value {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"meta":{"rc":"error","msg":"api.err.InvalidCSRFToken"},"data":[]}`)
+			return
+		}
+		io.WriteString(w, `{"meta":{"rc":"ok"},"data":[]}`)
+	}))
+	defer srv.Close()
+
+	api := newAPI(t, srv)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := api.Do("POST", "/api/s/default/cmd/stamgr", nil, nil); err != nil {
+				t.Errorf("Do: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if logins != 1 {
+		t.Errorf("Concurrent requests logged in %d times, want 1", logins)
+	}
+}
+
+// TestEmptyData checks that the various ways the controller reports
+// an empty list are all decoded as an empty slice.
+func TestEmptyData(t *testing.T) {
+	var body string // served for all requests other than logging in
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/login" {
+			io.WriteString(w, `{"meta":{"rc":"ok"},"data":[]}`)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	api := newAPI(t, srv)
+
+	tests := []struct {
+		desc string
+		v2   bool
+		body string
+	}{
+		{"null data", false, `{"meta":{"rc":"ok"},"data":null}`},
+		{"empty object data", false, `{"meta":{"rc":"ok"},"data":{}}`},
+		{"missing data", false, `{"meta":{"rc":"ok"}}`},
+		{"v2 null", true, `null`},
+		{"v2 empty object", true, `{}`},
+		{"v2 empty body", true, ``},
+	}
+	for _, test := range tests {
+		body = test.body
+		var dst []unifi.Client
+		var err error
+		if test.v2 {
+			err = api.DoV2("GET", "/v2/api/site/default/clients", nil, &dst)
+		} else {
+			err = api.Do("GET", "/api/s/default/stat/sta", nil, &dst)
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.desc, err)
+			continue
+		}
+		if dst == nil || len(dst) != 0 {
+			t.Errorf("%s: got %#v, want non-nil empty slice", test.desc, dst)
+		}
+	}
+}
+
+// newAPI returns an API for talking to srv.
+func newAPI(t *testing.T, srv *httptest.Server) *unifi.API {
+	t.Helper()
+	fn := filepath.Join(t.TempDir(), "auth")
+	err := unifi.InitFileAuthStore(fn, &unifi.Auth{
+		Username:       "admin",
+		Password:       "password",
+		ControllerHost: strings.TrimPrefix(srv.URL, "https://"),
+	})
+	if err != nil {
+		t.Fatalf("InitFileAuthStore: %v", err)
+	}
+	api, err := unifi.NewAPI(unifi.FileAuthStore(fn), nil)
+	if err != nil {
+		t.Fatalf("NewAPI: %v", err)
+	}
+	return api
+}
+
+func TestPingErrors(t *testing.T) {
+	// A controller that is starting up isn't an authentication failure.
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, "<html>Starting up</html>")
+	}))
+	defer srv.Close()
+	err := newAPI(t, srv).Ping(context.Background())
+	if !errors.Is(err, unifi.ErrControllerUnavailable) || errors.Is(err, unifi.ErrAuthFailed) {
+		t.Errorf("Ping of unavailable controller: %v", err)
+	}
+
+	// A wrong password is.
+	s := unifitest.NewServer()
+	defer s.Close()
+	s.Password = "wrong"
+	api, err := s.API(nil)
+	if err != nil {
+		t.Fatalf("NewAPI: %v", err)
+	}
+	s.Password = "password"
+	if err := api.Ping(context.Background()); !errors.Is(err, unifi.ErrAuthFailed) {
+		t.Errorf("Ping with wrong password: %v", err)
+	}
+
+	// So is a controller that demands a login in response to logging in,
+	// which must not be retried.
+	var logins int
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/login" {
+			logins++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, `{"meta":{"rc":"error","msg":"api.err.LoginRequired"},"data":[]}`)
+	}))
+	defer srv.Close()
+	if err := newAPI(t, srv).Ping(context.Background()); !errors.Is(err, unifi.ErrAuthFailed) {
+		t.Errorf("Ping of controller rejecting logins: %v", err)
+	}
+	if logins != 1 {
+		t.Errorf("Ping of controller rejecting logins tried to log in %d times, want 1", logins)
+	}
+}
+
+func TestFetchServerCert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	hostport := strings.TrimPrefix(srv.URL, "https://")
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	portNum, _ := strconv.Atoi(port)
+	want := unifi.CertFingerprint(srv.Certificate())
+
+	tests := []struct {
+		host string
+		port int
+	}{
+		{host, portNum},
+		{hostport, 0},
+		{"[" + host + "]", portNum},
+		{"[" + host + "]:1", portNum}, // an explicit port overrides the one in host
+	}
+	for _, test := range tests {
+		cert, err := unifi.FetchServerCert(test.host, test.port)
+		if err != nil {
+			t.Errorf("FetchServerCert(%q, %d): %v", test.host, test.port, err)
+			continue
+		}
+		if got := unifi.CertFingerprint(cert); got != want {
+			t.Errorf("FetchServerCert(%q, %d) has fingerprint %s, want %s", test.host, test.port, got, want)
+		}
+	}
+}
+
+func TestControllerHosts(t *testing.T) {
+	errStop := errors.New("stop")
+	tests := []struct {
+		host string
+		want string // host:port requested, or empty if NewAPI should fail
+	}{
+		{"unifi", "unifi:8443"},
+		{"unifi:443", "unifi:443"},
+		{"192.168.1.2", "192.168.1.2:8443"},
+		{"::1", "[::1]:8443"},
+		{"[::1]", "[::1]:8443"},
+		{"[::1]:443", "[::1]:443"},
+		{"fe80::1%eth0", "[fe80::1%eth0]:8443"},
+		{"", ""},
+		{"bad host", ""},
+		{"unifi:port", ""},
+	}
+	for i, test := range tests {
+		fn := filepath.Join(t.TempDir(), fmt.Sprintf("auth%d", i))
+		if err := unifi.InitFileAuthStore(fn, &unifi.Auth{ControllerHost: test.host}); err != nil {
+			t.Fatalf("InitFileAuthStore: %v", err)
+		}
+		var got string
+		api, err := unifi.NewAPI(unifi.FileAuthStore(fn), &unifi.Options{
+			DisableAutoLogin: true,
+			// Capture the requested address without contacting it.
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				got = req.URL.Host
+				return nil, errStop
+			},
+		})
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("NewAPI with host %q succeeded, want error", test.host)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewAPI with host %q: %v", test.host, err)
+			continue
+		}
+		if err := api.Do("GET", "/api/self?x=1", nil, nil); !errors.Is(err, errStop) {
+			t.Errorf("Request to host %q: got %v, want the proxy error", test.host, err)
+		}
+		if got != test.want {
+			t.Errorf("Request to host %q went to %q, want %q", test.host, got, test.want)
+		}
+	}
+}
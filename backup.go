@@ -0,0 +1,55 @@
+package unifi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DownloadBackup makes a backup of the controller and writes it to w.
+// The backup is in the controller's .unf format, suitable for restoring.
+func (api *API) DownloadBackup(site string, w io.Writer) error {
+	req := struct {
+		Cmd string `json:"cmd"`
+	}{"backup"}
+	var resp []struct {
+		URL string `json:"url"`
+	}
+	// Making a backup doesn't change the controller's configuration.
+	if err := api.post("/api/s/"+site+"/cmd/backup", &req, &resp, reqOpts{safe: true}); err != nil {
+		return err
+	}
+	if len(resp) == 0 || resp[0].URL == "" {
+		return fmt.Errorf("controller did not report a backup URL")
+	}
+
+	// The backup may be large, so stream it instead of going through doReq.
+	u, err := api.url(resp[0].URL)
+	if err != nil {
+		return err
+	}
+	hreq, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	api.setHeaders(hreq)
+	if err := api.wait(hreq.Context()); err != nil {
+		return err
+	}
+	hresp, err := api.hc.Do(hreq)
+	if err != nil {
+		return err
+	}
+	body, err := responseBody(hresp)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if hresp.StatusCode != 200 {
+		return &HTTPError{StatusCode: hresp.StatusCode, Status: hresp.Status}
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		return fmt.Errorf("reading backup: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,497 @@
+package unifi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ListKnownClients returns all clients known to a site, including ones not currently connected.
+// If within is positive, only clients seen within that duration are returned.
+func (api *API) ListKnownClients(site string, within time.Duration) ([]Client, error) {
+	req := struct {
+		Type   string `json:"type"`
+		Conn   string `json:"conn"`
+		Within int    `json:"within,omitempty"`
+	}{
+		Type:   "all",
+		Conn:   "all",
+		Within: withinHours(within),
+	}
+	var resp []Client
+	if err := api.post("/api/s/"+site+"/stat/alluser", &req, &resp, reqOpts{safe: true}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// withinHours converts d to the whole number of hours expected by the
+// controller's "within" parameters, rounding up.
+func withinHours(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Hour - 1) / time.Hour)
+}
+
+// ClientStat is a client's traffic over one interval of a report.
+type ClientStat struct {
+	Time             time.Time // start of the interval
+	RxBytes, TxBytes int64
+}
+
+func (cs *ClientStat) UnmarshalJSON(data []byte) error {
+	// Report values are floating point.
+	var aux struct {
+		Time    int64   `json:"time"` // milliseconds
+		RxBytes float64 `json:"rx_bytes"`
+		TxBytes float64 `json:"tx_bytes"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	cs.Time = unixMilliTime(aux.Time)
+	cs.RxBytes = int64(aux.RxBytes)
+	cs.TxBytes = int64(aux.TxBytes)
+	return nil
+}
+
+// ClientStats returns the traffic of the client with the given MAC address
+// between start and end, broken down by interval,
+// which should be one of "5minutes", "hourly" or "daily".
+func (api *API) ClientStats(site, mac string, interval string, start, end time.Time) ([]ClientStat, error) {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return nil, err
+	}
+	switch interval {
+	case "5minutes", "hourly", "daily":
+	default:
+		return nil, fmt.Errorf("unknown report interval %q", interval)
+	}
+	req := struct {
+		Attrs []string `json:"attrs"`
+		Start int64    `json:"start"` // milliseconds
+		End   int64    `json:"end"`   // milliseconds
+		MAC   string   `json:"mac"`
+	}{
+		Attrs: []string{"time", "rx_bytes", "tx_bytes"},
+		Start: start.UnixNano() / int64(time.Millisecond),
+		End:   end.UnixNano() / int64(time.Millisecond),
+		MAC:   mac,
+	}
+	var resp []ClientStat
+	if err := api.post("/api/s/"+site+"/stat/report/"+interval+".user", &req, &resp, reqOpts{safe: true}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DPIStat is the traffic of one application, as classified by deep packet inspection.
+type DPIStat struct {
+	Category    int `json:"cat"` // numeric category, as in the controller's UI
+	Application int `json:"app"` // numeric application within the category
+
+	RxBytes   int64 `json:"rx_bytes"`
+	TxBytes   int64 `json:"tx_bytes"`
+	RxPackets int64 `json:"rx_packets"`
+	TxPackets int64 `json:"tx_packets"`
+}
+
+// ClientDPIStats returns the traffic of the client with the given MAC address,
+// broken down by application. DPI must be enabled on the site's gateway.
+func (api *API) ClientDPIStats(site, mac string) ([]DPIStat, error) {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return nil, err
+	}
+	req := struct {
+		Type string   `json:"type"`
+		MACs []string `json:"macs"`
+	}{"by_app", []string{mac}}
+	var resp []struct {
+		MAC   string    `json:"mac"`
+		ByApp []DPIStat `json:"by_app"`
+	}
+	if err := api.post("/api/s/"+site+"/stat/stadpi", &req, &resp, reqOpts{safe: true}); err != nil {
+		return nil, err
+	}
+	for _, r := range resp {
+		if r.MAC == mac && r.ByApp != nil {
+			return r.ByApp, nil
+		}
+	}
+	return []DPIStat{}, nil
+}
+
+// stamgr issues a station manager command for the client with the given MAC address.
+func (api *API) stamgr(site, cmd, mac string) error {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return err
+	}
+	req := struct {
+		Cmd string `json:"cmd"`
+		MAC string `json:"mac"`
+	}{cmd, mac}
+	return api.post("/api/s/"+site+"/cmd/stamgr", &req, &json.RawMessage{}, reqOpts{})
+}
+
+// ReconnectClient disconnects the wireless client with the given MAC address,
+// forcing it to reassociate.
+//
+// The controller has no command to make a client renew its DHCP lease,
+// but most clients will do so when they reconnect,
+// so this is the closest equivalent for a client that holds a stale lease.
+func (api *API) ReconnectClient(site, mac string) error {
+	return api.stamgr(site, "kick-sta", mac)
+}
+
+// ClientCounts returns the number of connected clients on a site.
+// Guests are also counted as wired or wireless.
+func (api *API) ClientCounts(site string) (wired, wireless, guest int, err error) {
+	clients, err := api.ListClients(site)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	for _, c := range clients {
+		if c.Wired {
+			wired++
+		} else {
+			wireless++
+		}
+		if c.Guest {
+			guest++
+		}
+	}
+	return
+}
+
+// TotalThroughput returns the total bytes received and transmitted
+// by the connected clients on a site.
+func (api *API) TotalThroughput(site string) (rx, tx int64, err error) {
+	clients, err := api.ListClients(site)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, c := range clients {
+		rx += c.RxBytes
+		tx += c.TxBytes
+	}
+	return
+}
+
+// UserGroup is a group of clients sharing bandwidth limits.
+type UserGroup struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"`
+
+	// Per-client rate limits, in kbps. -1 means unlimited.
+	DownRateKbps int `json:"qos_rate_max_down"`
+	UpRateKbps   int `json:"qos_rate_max_up"`
+}
+
+func (api *API) ListUserGroups(site string) ([]UserGroup, error) {
+	var resp []UserGroup
+	if err := api.get("/api/s/"+site+"/list/usergroup", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AssignClientToUserGroup puts the client with the given MAC address into a user group.
+func (api *API) AssignClientToUserGroup(site, mac, groupID string) error {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return err
+	}
+	var users []struct {
+		ID string `json:"_id"`
+	}
+	if err := api.get("/api/s/"+site+"/stat/user/"+mac, &users, reqOpts{}); err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return ErrNotFound
+	}
+	req := struct {
+		UserGroupID string `json:"usergroup_id"`
+	}{groupID}
+	return api.post("/api/s/"+site+"/upd/user/"+users[0].ID, &req, &json.RawMessage{}, reqOpts{})
+}
+
+// BlockClient blocks the client with the given MAC address from connecting.
+func (api *API) BlockClient(site, mac string) error {
+	return api.stamgr(site, "block-sta", mac)
+}
+
+// UnblockClient reverses BlockClient.
+func (api *API) UnblockClient(site, mac string) error {
+	return api.stamgr(site, "unblock-sta", mac)
+}
+
+// BlockClients blocks many clients, issuing a bounded number of requests at once.
+// All the MAC addresses are validated before any are blocked.
+// It returns the errors for any clients that could not be blocked.
+func (api *API) BlockClients(site string, macs []string) error {
+	return api.stamgrAll(site, "block-sta", macs)
+}
+
+// UnblockClients reverses BlockClients.
+func (api *API) UnblockClients(site string, macs []string) error {
+	return api.stamgrAll(site, "unblock-sta", macs)
+}
+
+// maxConcurrentCmds bounds how many commands stamgrAll has in flight.
+const maxConcurrentCmds = 4
+
+// stamgrAll issues the same station manager command for many clients.
+func (api *API) stamgrAll(site, cmd string, macs []string) error {
+	for _, mac := range macs {
+		if _, err := normalizeMAC(mac); err != nil {
+			return err
+		}
+	}
+	errs := make([]error, len(macs))
+	sem := make(chan struct{}, maxConcurrentCmds)
+	var wg sync.WaitGroup
+	for i, mac := range macs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, mac string) {
+			defer func() { <-sem; wg.Done() }()
+			if err := api.stamgr(site, cmd, mac); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", mac, err)
+			}
+		}(i, mac)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// ClientFilter selects clients. The zero value selects all clients.
+type ClientFilter struct {
+	WiredOnly    bool
+	WirelessOnly bool
+	GuestOnly    bool
+	SSID         string // if set, only wireless clients of this network
+}
+
+// Match reports whether c is selected by the filter.
+func (f ClientFilter) Match(c Client) bool {
+	if f.WiredOnly && !c.Wired {
+		return false
+	}
+	if (f.WirelessOnly || f.SSID != "") && c.Wired {
+		return false
+	}
+	if f.GuestOnly && !c.Guest {
+		return false
+	}
+	if f.SSID != "" && c.ESSID != f.SSID {
+		return false
+	}
+	return true
+}
+
+// ListClientsFiltered is like ListClients, but only returns clients selected by f.
+func (api *API) ListClientsFiltered(site string, f ClientFilter) ([]Client, error) {
+	clients, err := api.ListClients(site)
+	if err != nil {
+		return nil, err
+	}
+	out := clients[:0]
+	for _, c := range clients {
+		if f.Match(c) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// Session is a period during which a client was associated with an access point.
+type Session struct {
+	APMAC      string `json:"ap_mac"`
+	Start, End time.Time
+	Duration   time.Duration
+
+	RxBytes int64 `json:"rx_bytes"`
+	TxBytes int64 `json:"tx_bytes"`
+
+	// TODO: other fields
+}
+
+func (s *Session) UnmarshalJSON(data []byte) error {
+	type Alias Session
+	aux := struct {
+		*Alias
+
+		Start    int64 `json:"assoc_time"`
+		End      int64 `json:"disassoc_time"`
+		Duration int64 `json:"duration"` // seconds
+	}{Alias: (*Alias)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.Start = unixTime(aux.Start)
+	s.End = unixTime(aux.End)
+	s.Duration = time.Duration(aux.Duration) * time.Second
+	return nil
+}
+
+// ClientSessions returns the sessions of the client with the given MAC address
+// between start and end, showing which access points it was associated with.
+func (api *API) ClientSessions(site, mac string, start, end time.Time) ([]Session, error) {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return nil, err
+	}
+	req := struct {
+		Type  string `json:"type"`
+		Start int64  `json:"start"`
+		End   int64  `json:"end"`
+		MAC   string `json:"mac"`
+	}{"all", start.Unix(), end.Unix(), mac}
+	var resp []Session
+	if err := api.post("/api/s/"+site+"/stat/session", &req, &resp, reqOpts{safe: true}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// forgetBatchSize bounds how many clients are forgotten per request.
+const forgetBatchSize = 100
+
+// ForgetOfflineClients makes the controller forget the known clients
+// that have not been seen for longer than olderThan.
+// It returns how many clients were forgotten, which may be non-zero even on error.
+func (api *API) ForgetOfflineClients(site string, olderThan time.Duration) (int, error) {
+	if olderThan <= 0 {
+		return 0, fmt.Errorf("bad age %v; it must be positive", olderThan)
+	}
+	clients, err := api.ListKnownClients(site, 0)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-olderThan)
+	var macs []string
+	for _, c := range clients {
+		if !c.LastSeen.IsZero() && c.LastSeen.Before(cutoff) {
+			macs = append(macs, c.MAC)
+		}
+	}
+	n := 0
+	for len(macs) > 0 {
+		batch := macs
+		if len(batch) > forgetBatchSize {
+			batch = batch[:forgetBatchSize]
+		}
+		macs = macs[len(batch):]
+		req := struct {
+			Cmd  string   `json:"cmd"`
+			MACs []string `json:"macs"`
+		}{"forget-sta", batch}
+		if err := api.post("/api/s/"+site+"/cmd/stamgr", &req, &json.RawMessage{}, reqOpts{}); err != nil {
+			return n, err
+		}
+		n += len(batch)
+	}
+	return n, nil
+}
+
+// ClientsByAP returns the connected wireless clients of a site,
+// keyed by the name of the access point each is associated with.
+// Access points without a name are keyed by their MAC address.
+func (api *API) ClientsByAP(site string) (map[string][]Client, error) {
+	clients, err := api.ListClients(site)
+	if err != nil {
+		return nil, err
+	}
+	devs, err := api.ListDevices(site)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string) // MAC => name
+	for _, dev := range devs {
+		if dev.Name != "" {
+			names[dev.MAC] = dev.Name
+		}
+	}
+	m := make(map[string][]Client)
+	for _, c := range clients {
+		if c.Wired || c.APMAC == "" {
+			continue
+		}
+		key, ok := names[c.APMAC]
+		if !ok {
+			key = c.APMAC
+		}
+		m[key] = append(m[key], c)
+	}
+	return m, nil
+}
+
+// IsClientPresent reports whether the client with the given MAC address is
+// currently connected to a site, and when it was last seen.
+// If it is not connected, the last seen time is from the site's known clients,
+// and is zero if the client has never been seen.
+//
+// Many phones use a randomized ("private") MAC address for each network,
+// so the address to check is the one shown by the controller, not the device's hardware address.
+func (api *API) IsClientPresent(site, mac string) (bool, time.Time, error) {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	clients, err := api.ListClients(site)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	for _, c := range clients {
+		if m, _ := normalizeMAC(c.MAC); m == mac {
+			return true, c.LastSeen, nil
+		}
+	}
+	known, err := api.ListKnownClients(site, 0)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	for _, c := range known {
+		if m, _ := normalizeMAC(c.MAC); m == mac {
+			return false, c.LastSeen, nil
+		}
+	}
+	return false, time.Time{}, nil
+}
+
+// ExpireStaleAuthorizations unauthorizes the guests of a site
+// whose authorization has ended but which the controller has not yet expired.
+// It carries on past failures, returning how many guests were unauthorized
+// along with an error joining those for each guest that could not be.
+func (api *API) ExpireStaleAuthorizations(site string) (int, error) {
+	var guests []struct {
+		MAC     string `json:"mac"`
+		End     int64  `json:"end"`
+		Expired bool   `json:"expired"`
+	}
+	if err := api.get("/api/s/"+site+"/stat/guest", &guests, reqOpts{}); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	n := 0
+	var errs []error
+	for _, g := range guests {
+		end := unixTime(g.End)
+		if g.Expired || end.IsZero() || end.After(now) {
+			continue
+		}
+		if err := api.stamgr(site, "unauthorize-guest", g.MAC); err != nil {
+			errs = append(errs, fmt.Errorf("guest %s: %w", g.MAC, err))
+			continue
+		}
+		n++
+	}
+	return n, errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+package unifi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// The operating system of a UniFi OS console (such as a Cloud Key Gen2 or
+// Dream Machine) has its own API, separate from the Network application
+// that the rest of this package uses. It is served on port 443,
+// with its own login, and its responses have no envelope.
+
+// RebootController reboots the controller itself, which must be a UniFi OS console.
+// This interrupts all management, and on consoles that are also the gateway,
+// all network connectivity, until it comes back up.
+// It returns once the console accepts the request, without waiting for that.
+//
+// To guard against accidents, confirmHost must be the same as the ControllerHost
+// of the API's Auth. The console is contacted on port 443 regardless of
+// any port in ControllerHost, and logged in to using the same credentials.
+func (api *API) RebootController(confirmHost string) error {
+	if confirmHost == "" || confirmHost != api.auth.ControllerHost {
+		return fmt.Errorf("not rebooting controller %q: confirmation %q does not match", api.auth.ControllerHost, confirmHost)
+	}
+	if api.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	name, _ := splitControllerHost(api.auth.ControllerHost)
+	base := url.URL{Scheme: "https", Host: net.JoinHostPort(name, "443")}
+
+	api.mu.Lock()
+	password := api.password
+	api.mu.Unlock()
+	login := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{api.auth.Username, password}
+	resp, err := api.consoleDo("POST", base.String()+"/api/auth/login", &login, "")
+	if err != nil {
+		return fmt.Errorf("logging in to console: %w", err)
+	}
+	// The console issues its own CSRF token, distinct from the Network application's.
+	csrf := resp.Header.Get("X-CSRF-Token")
+
+	if _, err := api.consoleDo("POST", base.String()+"/api/system/reboot", struct{}{}, csrf); err != nil {
+		return fmt.Errorf("rebooting console: %w", err)
+	}
+	return nil
+}
+
+// consoleDo sends a request to the UniFi OS console API,
+// returning the response (with its body closed) if it was successful.
+func (api *API) consoleDo(method, u string, src interface{}, csrf string) (*http.Response, error) {
+	body, err := json.Marshal(src)
+	if err != nil {
+		panic("internal error marshaling JSON " + method + " body: " + err.Error())
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	api.setHeaders(req)
+	req.Header.Del("X-CSRF-Token")
+	if csrf != "" {
+		req.Header.Set("X-CSRF-Token", csrf)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := api.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := api.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	closeBody(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return resp, nil
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func main() {
-	api, err := unifi.NewAPI(unifi.FileAuthStore(unifi.DefaultAuthFile))
+	api, err := unifi.NewAPI(unifi.FileAuthStore(unifi.DefaultAuthFile), &unifi.Options{ReadOnly: true})
 	if err != nil {
 		log.Fatalf("unifi.NewClient: %v", err)
 	}
@@ -21,7 +21,7 @@ func main() {
 		log.Fatalf("usage: %s [on|off]", os.Args[0])
 	}
 
-	api, err := unifi.NewAPI(unifi.FileAuthStore(unifi.DefaultAuthFile))
+	api, err := unifi.NewAPI(unifi.FileAuthStore(unifi.DefaultAuthFile), nil)
 	if err != nil {
 		log.Fatalf("unifi.NewClient: %v", err)
 	}
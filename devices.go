@@ -0,0 +1,327 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Device is a piece of UniFi hardware managed by the controller.
+type Device struct {
+	ID      string `json:"_id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"` // "uap", "usw", "ugw", "udm", etc.
+	Model   string `json:"model"`
+	Version string `json:"version"` // firmware version
+
+	Upgradable        bool   `json:"upgradable"`          // whether newer firmware is available
+	UpgradeToFirmware string `json:"upgrade_to_firmware"` // the version that would be upgraded to
+
+	MAC string `json:"mac"`
+	IP  string `json:"ip"`
+
+	Adopted  bool        `json:"adopted"`
+	State    DeviceState `json:"state"`
+	LastSeen time.Time
+	Uptime   time.Duration
+
+	Radios []RadioConfig `json:"radio_table"` // only for access points
+	Ports  []Port        `json:"port_table"`
+
+	// TODO: other fields
+
+	// Raw is the JSON object this was decoded from,
+	// for access to fields not otherwise modeled.
+	Raw json.RawMessage `json:"-"`
+}
+
+func (d *Device) UnmarshalJSON(data []byte) error {
+	type Alias Device
+	aux := struct {
+		*Alias
+
+		LastSeen int64 `json:"last_seen"`
+		Uptime   int64 `json:"uptime"` // seconds
+	}{Alias: (*Alias)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.Raw = append(json.RawMessage(nil), data...)
+	d.LastSeen = unixTime(aux.LastSeen)
+	d.Uptime = time.Duration(aux.Uptime) * time.Second
+	return nil
+}
+
+// DeviceState is the state of a device as reported by the controller.
+type DeviceState int
+
+const (
+	DeviceDisconnected    DeviceState = 0
+	DeviceConnected       DeviceState = 1
+	DevicePendingAdoption DeviceState = 2
+	DeviceUpgrading       DeviceState = 4
+	DeviceProvisioning    DeviceState = 5
+	DeviceHeartbeatMissed DeviceState = 6
+	DeviceAdopting        DeviceState = 7
+)
+
+func (s DeviceState) String() string {
+	switch s {
+	case DeviceDisconnected:
+		return "disconnected"
+	case DeviceConnected:
+		return "connected"
+	case DevicePendingAdoption:
+		return "pending adoption"
+	case DeviceUpgrading:
+		return "upgrading"
+	case DeviceProvisioning:
+		return "provisioning"
+	case DeviceHeartbeatMissed:
+		return "heartbeat missed"
+	case DeviceAdopting:
+		return "adopting"
+	}
+	return fmt.Sprintf("DeviceState(%d)", int(s))
+}
+
+// RadioConfig is the configuration of one radio of an access point.
+// When passed to SetRadioSettings, fields other than Band that are
+// the zero value leave the corresponding setting unchanged.
+type RadioConfig struct {
+	Band         string // "ng" (2.4 GHz) or "na" (5 GHz)
+	AutoChannel  bool   // whether the channel is chosen automatically
+	Channel      int    // only used if AutoChannel is false
+	ChannelWidth int    // MHz
+	TxPower      string // "auto", "high", "medium", "low" or "custom"
+	TxPowerDBm   int    // only used if the TxPower setting is "custom"
+}
+
+func (rc *RadioConfig) UnmarshalJSON(data []byte) error {
+	// Numeric fields may be numbers or strings, and "auto" is used for some.
+	var aux struct {
+		Radio       string          `json:"radio"`
+		Channel     json.RawMessage `json:"channel"`
+		HT          json.RawMessage `json:"ht"`
+		TxPowerMode string          `json:"tx_power_mode"`
+		TxPower     json.RawMessage `json:"tx_power"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	rc.Band = aux.Radio
+	rc.AutoChannel = string(aux.Channel) == `"auto"`
+	rc.Channel = looseInt(aux.Channel)
+	rc.ChannelWidth = looseInt(aux.HT)
+	rc.TxPower = aux.TxPowerMode
+	rc.TxPowerDBm = looseInt(aux.TxPower)
+	return nil
+}
+
+// looseInt decodes a JSON number or a string containing one,
+// returning zero for anything else (such as "auto").
+func looseInt(raw json.RawMessage) int {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		raw = json.RawMessage(s)
+	}
+	n, _ := strconv.Atoi(string(raw))
+	return n
+}
+
+func (api *API) ListDevices(site string) ([]Device, error) {
+	var resp []Device
+	if err := api.get("/api/s/"+site+"/stat/device", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DevicesWithUpdates returns the devices of a site that have newer firmware available.
+// See UpgradeDevice for a way to install it.
+func (api *API) DevicesWithUpdates(site string) ([]Device, error) {
+	devs, err := api.ListDevices(site)
+	if err != nil {
+		return nil, err
+	}
+	out := devs[:0]
+	for _, dev := range devs {
+		if dev.Upgradable {
+			out = append(out, dev)
+		}
+	}
+	return out, nil
+}
+
+// devmgr issues a device manager command for the device with the given MAC address.
+func (api *API) devmgr(site, cmd, mac string) error {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return err
+	}
+	req := struct {
+		Cmd string `json:"cmd"`
+		MAC string `json:"mac"`
+	}{cmd, mac}
+	return api.post("/api/s/"+site+"/cmd/devmgr", &req, &json.RawMessage{}, reqOpts{})
+}
+
+// StartSpectrumScan starts an RF scan on the access point with the given MAC address.
+// The access point's radios are unavailable to clients while the scan runs.
+func (api *API) StartSpectrumScan(site, mac string) error {
+	return api.devmgr(site, "spectrum-scan", mac)
+}
+
+// UpgradeDevice upgrades the device with the given MAC address
+// to the latest firmware known to the controller.
+func (api *API) UpgradeDevice(site, mac string) error {
+	return api.devmgr(site, "upgrade", mac)
+}
+
+// UpgradeDeviceToURL upgrades the device with the given MAC address
+// to the firmware image at the given URL.
+func (api *API) UpgradeDeviceToURL(site, mac, url string) error {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return err
+	}
+	req := struct {
+		Cmd string `json:"cmd"`
+		MAC string `json:"mac"`
+		URL string `json:"url"`
+	}{"upgrade-external", mac, url}
+	return api.post("/api/s/"+site+"/cmd/devmgr", &req, &json.RawMessage{}, reqOpts{})
+}
+
+// getDevice fetches the device with the given MAC address, decoding it into dst.
+func (api *API) getDevice(ctx context.Context, site, mac string, dst interface{}) error {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return err
+	}
+	var resp []json.RawMessage
+	if err := api.get("/api/s/"+site+"/stat/device/"+mac, &resp, reqOpts{ctx: ctx}); err != nil {
+		return err
+	}
+	if len(resp) == 0 {
+		return ErrNotFound
+	}
+	return json.Unmarshal(resp[0], dst)
+}
+
+// updateDevice updates the configuration of the device with the given ID.
+// Fields not present in src are left unchanged.
+func (api *API) updateDevice(site, id string, src interface{}) error {
+	return api.put("/api/s/"+site+"/rest/device/"+id, src, &json.RawMessage{}, reqOpts{})
+}
+
+// SetRadioSettings sets the configuration of one radio of the access point
+// with the given MAC address. The radio is selected by radio.Band.
+func (api *API) SetRadioSettings(site, mac string, radio RadioConfig) error {
+	// The radio table must be written as a whole,
+	// so preserve everything about it that RadioConfig doesn't model.
+	var dev struct {
+		ID         string                   `json:"_id"`
+		RadioTable []map[string]interface{} `json:"radio_table"`
+	}
+	if err := api.getDevice(context.Background(), site, mac, &dev); err != nil {
+		return err
+	}
+	found := false
+	for _, r := range dev.RadioTable {
+		if r["radio"] != radio.Band {
+			continue
+		}
+		found = true
+		if radio.AutoChannel {
+			r["channel"] = "auto"
+		} else if radio.Channel != 0 {
+			r["channel"] = radio.Channel
+		}
+		if radio.ChannelWidth != 0 {
+			r["ht"] = radio.ChannelWidth
+		}
+		if radio.TxPower != "" {
+			r["tx_power_mode"] = radio.TxPower
+		}
+		if radio.TxPowerDBm != 0 {
+			r["tx_power"] = radio.TxPowerDBm
+		}
+	}
+	if !found {
+		return fmt.Errorf("device %s has no %q radio", mac, radio.Band)
+	}
+	req := struct {
+		RadioTable []map[string]interface{} `json:"radio_table"`
+	}{dev.RadioTable}
+	return api.updateDevice(site, dev.ID, &req)
+}
+
+// SetDeviceName sets the name of the device with the given MAC address.
+// An empty name reverts to the controller's default name for the device.
+func (api *API) SetDeviceName(site, mac, name string) error {
+	var dev struct {
+		ID string `json:"_id"`
+	}
+	if err := api.getDevice(context.Background(), site, mac, &dev); err != nil {
+		return err
+	}
+	req := struct {
+		Name string `json:"name"`
+	}{name}
+	return api.updateDevice(site, dev.ID, &req)
+}
+
+// ForceProvisionDevice makes the controller re-send its configuration
+// to the device with the given MAC address.
+func (api *API) ForceProvisionDevice(site, mac string) error {
+	return api.devmgr(site, "force-provision", mac)
+}
+
+// ForceProvisionSite force-provisions every adopted device on a site.
+// Failures don't stop the others; the returned error joins them all,
+// each annotated with its device.
+func (api *API) ForceProvisionSite(site string) error {
+	devs, err := api.ListDevices(site)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, dev := range devs {
+		if !dev.Adopted {
+			continue
+		}
+		if err := api.ForceProvisionDevice(site, dev.MAC); err != nil {
+			errs = append(errs, fmt.Errorf("device %s (%s): %w", dev.Name, dev.MAC, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WaitForDeviceState waits until the device with the given MAC address
+// reaches the given state, checking every poll interval.
+// It returns early if ctx is done, or if checking the device's state fails.
+func (api *API) WaitForDeviceState(ctx context.Context, site, mac string, state DeviceState, poll time.Duration) error {
+	if poll <= 0 {
+		return fmt.Errorf("bad poll interval %v; it must be positive", poll)
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		var dev Device
+		if err := api.getDevice(ctx, site, mac, &dev); err != nil {
+			return err
+		}
+		if dev.State == state {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Event is an entry in a site's event log.
+type Event struct {
+	ID        string `json:"_id"`
+	Key       string `json:"key"` // e.g. "EVT_WU_Connected"
+	Subsystem string `json:"subsystem"`
+	Message   string `json:"msg"`
+
+	Time time.Time
+
+	// TODO: other fields
+
+	// Raw is the JSON object this was decoded from,
+	// for access to fields not otherwise modeled.
+	Raw json.RawMessage `json:"-"`
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type Alias Event
+	aux := struct {
+		*Alias
+
+		Time int64 `json:"time"` // milliseconds
+	}{Alias: (*Alias)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	e.Raw = append(json.RawMessage(nil), data...)
+	e.Time = unixMilliTime(aux.Time)
+	return nil
+}
+
+// SubscribeOptions holds optional parameters for SubscribeEvents.
+type SubscribeOptions struct {
+	// ReconnectAttempts is how many consecutive attempts are made to
+	// re-establish a dropped connection before the stream ends.
+	// If zero, 10 are made; if negative, there is no limit.
+	ReconnectAttempts int
+
+	// MaxBackoff bounds the delay before each reconnection attempt,
+	// which starts at about a second and doubles after each failure.
+	// If zero, one minute is used.
+	MaxBackoff time.Duration
+}
+
+// An EventStream delivers the events of a site as they happen. See SubscribeEvents.
+type EventStream struct {
+	// C receives the events. It is closed when the stream ends.
+	C <-chan Event
+
+	err error
+}
+
+// Err returns why the stream ended. It may only be called once C is closed.
+// If the stream's context was done, it returns the context's error.
+func (s *EventStream) Err() error {
+	return s.err
+}
+
+// SubscribeEvents streams the events of a site from the controller's websocket.
+// opts may be nil, which is equivalent to a zero SubscribeOptions.
+// An error is returned if the initial connection can't be made.
+//
+// If the connection drops, as when the controller restarts, it is re-established
+// (logging in again if needed) after a jittered, exponentially increasing delay.
+// Events that occur while disconnected are not delivered; ListEvents may be
+// used to catch up. The stream ends when ctx is done, or when reconnecting
+// fails more times in a row than opts allows.
+func (api *API) SubscribeEvents(ctx context.Context, site string, opts *SubscribeOptions) (*EventStream, error) {
+	var o SubscribeOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.ReconnectAttempts == 0 {
+		o.ReconnectAttempts = 10
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = time.Minute
+	}
+
+	conn, err := api.dialEvents(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan Event)
+	s := &EventStream{C: ch}
+	go func() {
+		defer close(ch)
+		s.err = api.streamEvents(ctx, site, conn, ch, o)
+	}()
+	return s, nil
+}
+
+// dialEvents connects to the event websocket of a site,
+// first making sure that there is a valid session.
+func (api *API) dialEvents(ctx context.Context, site string) (*wsConn, error) {
+	if err := api.EnsureAuthenticated(ctx); err != nil {
+		return nil, err
+	}
+	return api.dialWebsocket(ctx, "/wss/s/"+site+"/events")
+}
+
+// streamEvents delivers events from conn, reconnecting whenever it drops.
+func (api *API) streamEvents(ctx context.Context, site string, conn *wsConn, ch chan<- Event, o SubscribeOptions) error {
+	for {
+		readEvents(ctx, conn, ch)
+		conn.close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		backoff := time.Second
+		for attempt := 1; ; attempt++ {
+			if backoff > o.MaxBackoff {
+				backoff = o.MaxBackoff
+			}
+			// Wait between half and all of the backoff, so that clients
+			// disconnected together don't all reconnect together.
+			d := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d):
+			}
+			var err error
+			if conn, err = api.dialEvents(ctx, site); err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if o.ReconnectAttempts > 0 && attempt >= o.ReconnectAttempts {
+				return fmt.Errorf("reconnecting to event stream failed %d times: %w", attempt, err)
+			}
+			backoff *= 2
+		}
+	}
+}
+
+// readEvents delivers events from conn until it fails or ctx is done.
+func readEvents(ctx context.Context, conn *wsConn, ch chan<- Event) {
+	for {
+		msg, err := conn.readMessage()
+		if err != nil {
+			return
+		}
+		var m struct {
+			Meta struct {
+				Message string `json:"message"`
+			} `json:"meta"`
+			Data []Event `json:"data"`
+		}
+		if err := json.Unmarshal(msg, &m); err != nil || m.Meta.Message != "events" {
+			continue // another kind of notification, such as a device update
+		}
+		for _, e := range m.Data {
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// ListEvents returns the most recent events for a site, newest first.
+// If within is positive, only events from within that duration are returned.
+func (api *API) ListEvents(site string, within time.Duration) ([]Event, error) {
+	events, _, err := api.listEvents(site, within, 0, 0)
+	return events, err
+}
+
+// ListEventsPage is like ListEvents, but returns at most limit events,
+// skipping the first start. It also returns the total number of matching events.
+func (api *API) ListEventsPage(site string, within time.Duration, start, limit int) (events []Event, total int, err error) {
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("bad limit %d", limit)
+	}
+	return api.listEvents(site, within, start, limit)
+}
+
+// EventCount returns the number of events for a site.
+// If within is positive, only events from within that duration are counted.
+func (api *API) EventCount(site string, within time.Duration) (int, error) {
+	// A limit of zero would mean no limit, so fetch a single event.
+	_, total, err := api.listEvents(site, within, 0, 1)
+	return total, err
+}
+
+// AlarmCount returns the number of alarms for a site that haven't been archived.
+func (api *API) AlarmCount(site string) (int, error) {
+	var resp []struct {
+		Count int `json:"count"`
+	}
+	if err := api.get("/api/s/"+site+"/cnt/alarm?archived=false", &resp, reqOpts{}); err != nil {
+		return 0, err
+	}
+	if len(resp) == 0 {
+		return 0, nil
+	}
+	return resp[0].Count, nil
+}
+
+func (api *API) listEvents(site string, within time.Duration, start, limit int) ([]Event, int, error) {
+	req := struct {
+		Sort   string `json:"_sort"`
+		Within int    `json:"within,omitempty"`
+		Start  int    `json:"_start,omitempty"`
+		Limit  int    `json:"_limit,omitempty"`
+	}{
+		Sort:   "-time",
+		Within: withinHours(within),
+		Start:  start,
+		Limit:  limit,
+	}
+	var resp []Event
+	var total int
+	if err := api.post("/api/s/"+site+"/stat/event", &req, &resp, reqOpts{safe: true, count: &total}); err != nil {
+		return nil, 0, err
+	}
+	return resp, total, nil
+}
+
+// SystemMessage is a controller-wide notification, such as that new firmware
+// is available or that cloud access has been lost.
+// These are distinct from the events and alarms of a particular site.
+type SystemMessage struct {
+	ID      string `json:"_id"`
+	Key     string `json:"key"`
+	Message string `json:"msg"`
+
+	Time time.Time
+
+	// TODO: other fields
+}
+
+func (m *SystemMessage) UnmarshalJSON(data []byte) error {
+	type Alias SystemMessage
+	aux := struct {
+		*Alias
+
+		Time int64 `json:"time"` // milliseconds
+	}{Alias: (*Alias)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	m.Time = unixMilliTime(aux.Time)
+	return nil
+}
+
+// SystemMessages returns the controller's current system messages.
+// Dismissed messages are not included.
+func (api *API) SystemMessages() ([]SystemMessage, error) {
+	var resp []SystemMessage
+	if err := api.get("/api/s/default/list/notification", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DismissSystemMessage dismisses the system message with the given ID.
+func (api *API) DismissSystemMessage(id string) error {
+	req := struct {
+		Archived bool `json:"archived"`
+	}{true}
+	return api.put("/api/s/default/rest/notification/"+id, &req, &json.RawMessage{}, reqOpts{})
+}
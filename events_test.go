@@ -0,0 +1,121 @@
+package unifi_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsymonds/unifi"
+)
+
+// TestSubscribeEvents checks that the event stream survives a dropped connection,
+// and ends once reconnecting keeps failing.
+func TestSubscribeEvents(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		conns int
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wss/s/default/events" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"meta":{"rc":"ok"},"data":[]}`)
+			return
+		}
+		mu.Lock()
+		conns++
+		n := conns
+		mu.Unlock()
+		if n > 2 {
+			// The controller has gone away for good.
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+			base64.StdEncoding.EncodeToString(sum[:]))
+
+		// A ping must be answered.
+		writeFrame(rw, 0x9, "hi")
+		if op, payload := readFrame(t, rw.Reader); op != 0xA || payload != "hi" {
+			t.Errorf("Reply to ping was opcode %#x with %q, want pong with %q", op, payload, "hi")
+		}
+		writeFrame(rw, 0x1, `{"meta":{"rc":"ok","message":"sta:sync"},"data":[{}]}`)
+		writeFrame(rw, 0x1, fmt.Sprintf(`{"meta":{"rc":"ok","message":"events"},"data":[{"key":"EVT_%d","time":1600000000000}]}`, n))
+		// Then the connection drops.
+	}))
+	defer srv.Close()
+
+	api := newAPI(t, srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := api.SubscribeEvents(ctx, "default", &unifi.SubscribeOptions{
+		ReconnectAttempts: 2,
+		MaxBackoff:        10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SubscribeEvents: %v", err)
+	}
+	var keys []string
+	for e := range s.C {
+		keys = append(keys, e.Key)
+	}
+	if len(keys) != 2 || keys[0] != "EVT_1" || keys[1] != "EVT_2" {
+		t.Errorf("Stream delivered events %q, want [EVT_1 EVT_2]", keys)
+	}
+	var herr *unifi.HTTPError
+	if err := s.Err(); !errors.As(err, &herr) || herr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Stream ended with %v, want a 503 HTTPError", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 4 {
+		t.Errorf("Stream connected %d times, want 4 (two failing)", conns)
+	}
+}
+
+// writeFrame writes an unmasked websocket frame, as a server does.
+func writeFrame(w *bufio.ReadWriter, op byte, payload string) {
+	w.WriteByte(0x80 | op)
+	if len(payload) < 126 {
+		w.WriteByte(byte(len(payload)))
+	} else {
+		w.Write([]byte{126, byte(len(payload) >> 8), byte(len(payload))})
+	}
+	w.WriteString(payload)
+	w.Flush()
+}
+
+// readFrame reads a small masked websocket frame, as a client sends.
+func readFrame(t *testing.T, r *bufio.Reader) (op byte, payload string) {
+	buf := make([]byte, 6)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Errorf("Reading frame: %v", err)
+		return 0, ""
+	}
+	p := make([]byte, buf[1]&0x7F)
+	if _, err := io.ReadFull(r, p); err != nil {
+		t.Errorf("Reading frame: %v", err)
+		return 0, ""
+	}
+	for i := range p {
+		p[i] ^= buf[2+i%4]
+	}
+	return buf[0] & 0x0F, string(p)
+}
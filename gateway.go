@@ -0,0 +1,111 @@
+package unifi
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// isGateway reports whether a device type is a gateway.
+func isGateway(typ string) bool {
+	switch typ {
+	case "ugw", "udm", "uxg":
+		return true
+	}
+	return false
+}
+
+// getGateway fetches a site's gateway device, decoding it into dst.
+func (api *API) getGateway(site string, dst interface{}) error {
+	var devs []json.RawMessage
+	if err := api.get("/api/s/"+site+"/stat/device", &devs, reqOpts{}); err != nil {
+		return err
+	}
+	for _, raw := range devs {
+		var dev struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &dev); err != nil {
+			return err
+		}
+		if isGateway(dev.Type) {
+			return json.Unmarshal(raw, dst)
+		}
+	}
+	return ErrNotFound
+}
+
+// GatewayStat holds the WAN statistics of a gateway.
+type GatewayStat struct {
+	Name string
+	MAC  string
+
+	PublicIP         string // the WAN address; may be private if behind another NAT
+	RxBytes, TxBytes int64
+	RxRate, TxRate   int64 // bytes per second
+	Uptime           time.Duration
+}
+
+// GatewayStats returns the WAN statistics of a site's gateway,
+// or ErrNotFound if it has none.
+func (api *API) GatewayStats(site string) (GatewayStat, error) {
+	var gw struct {
+		Name   string `json:"name"`
+		MAC    string `json:"mac"`
+		Uptime int64  `json:"uptime"` // seconds
+		WAN    struct {
+			IP      string  `json:"ip"`
+			RxBytes int64   `json:"rx_bytes"`
+			TxBytes int64   `json:"tx_bytes"`
+			RxRate  float64 `json:"rx_bytes-r"`
+			TxRate  float64 `json:"tx_bytes-r"`
+		} `json:"wan1"`
+	}
+	if err := api.getGateway(site, &gw); err != nil {
+		return GatewayStat{}, err
+	}
+	return GatewayStat{
+		Name:     gw.Name,
+		MAC:      gw.MAC,
+		PublicIP: gw.WAN.IP,
+		RxBytes:  gw.WAN.RxBytes,
+		TxBytes:  gw.WAN.TxBytes,
+		RxRate:   int64(gw.WAN.RxRate),
+		TxRate:   int64(gw.WAN.TxRate),
+		Uptime:   time.Duration(gw.Uptime) * time.Second,
+	}, nil
+}
+
+// PublicIPInfo describes a site's WAN address.
+type PublicIPInfo struct {
+	IP  string // may be private if behind another NAT
+	ISP string
+
+	// These are only set if the controller has geolocated the address.
+	City    string
+	Country string // ISO 3166-1 alpha-2 code
+}
+
+// PublicIP returns the WAN address of a site's gateway,
+// or ErrNotFound if it has none.
+func (api *API) PublicIP(site string) (PublicIPInfo, error) {
+	var gw struct {
+		WAN struct {
+			IP string `json:"ip"`
+		} `json:"wan1"`
+		GeoInfo map[string]struct {
+			ISP     string `json:"isp_name"`
+			City    string `json:"city"`
+			Country string `json:"country_code"`
+		} `json:"geo_info"` // keyed by WAN interface name ("WAN", "WAN2")
+	}
+	if err := api.getGateway(site, &gw); err != nil {
+		return PublicIPInfo{}, err
+	}
+	geo := gw.GeoInfo["WAN"]
+	return PublicIPInfo{
+		IP:      gw.WAN.IP,
+		ISP:     geo.ISP,
+		City:    geo.City,
+		Country: geo.Country,
+	}, nil
+}
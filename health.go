@@ -0,0 +1,81 @@
+package unifi
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SubsystemHealth is the health of one subsystem of a site.
+type SubsystemHealth struct {
+	Subsystem string `json:"subsystem"` // "wan", "wlan", "lan", "www", etc.
+	Status    string `json:"status"`    // "ok", "warning", "error", "unknown"
+
+	// These are only reported for the "www" subsystem.
+	Latency time.Duration
+	Uptime  time.Duration
+	Drops   int `json:"drops"`
+
+	// Device and client counts. Each is only reported for the relevant subsystems.
+	NumAPs      int `json:"num_ap"`    // "wlan"
+	NumSwitches int `json:"num_sw"`    // "lan"
+	NumGateways int `json:"num_gw"`    // "wan"
+	NumUsers    int `json:"num_user"`  // "wlan", "lan"
+	NumGuests   int `json:"num_guest"` // "wlan", "lan"
+
+	// TODO: other fields
+}
+
+func (h *SubsystemHealth) UnmarshalJSON(data []byte) error {
+	type Alias SubsystemHealth
+	aux := struct {
+		*Alias
+
+		Latency int64 `json:"latency"` // milliseconds
+		Uptime  int64 `json:"uptime"`  // seconds
+	}{Alias: (*Alias)(h)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	h.Latency = time.Duration(aux.Latency) * time.Millisecond
+	h.Uptime = time.Duration(aux.Uptime) * time.Second
+	return nil
+}
+
+// HealthStatus reports the health of each subsystem of a site.
+func (api *API) HealthStatus(site string) ([]SubsystemHealth, error) {
+	var resp []SubsystemHealth
+	if err := api.get("/api/s/"+site+"/stat/health", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GlobalStats holds totals across all of a controller's sites.
+type GlobalStats struct {
+	Sites int
+
+	APs, Switches, Gateways int
+	Users, Guests           int // connected clients
+}
+
+// GlobalStats returns totals across all the sites that the API can access.
+// It uses a single request, so it is cheaper than calling HealthStatus for each site.
+func (api *API) GlobalStats() (GlobalStats, error) {
+	var resp []struct {
+		Health []SubsystemHealth `json:"health"`
+	}
+	if err := api.get("/api/stat/sites", &resp, reqOpts{}); err != nil {
+		return GlobalStats{}, err
+	}
+	gs := GlobalStats{Sites: len(resp)}
+	for _, site := range resp {
+		for _, h := range site.Health {
+			gs.APs += h.NumAPs
+			gs.Switches += h.NumSwitches
+			gs.Gateways += h.NumGateways
+			gs.Users += h.NumUsers
+			gs.Guests += h.NumGuests
+		}
+	}
+	return gs, nil
+}
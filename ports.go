@@ -0,0 +1,121 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// PortProfile is a named switch port configuration.
+type PortProfile struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"`
+
+	Forward         string `json:"forward"` // "all", "native", "customize" or "disabled"
+	NativeNetworkID string `json:"native_networkconf_id"`
+	PoEMode         string `json:"poe_mode"` // "auto", "pasv24", "passthrough" or "off"
+	Isolation       bool   `json:"isolation"`
+
+	// TODO: other fields
+}
+
+func (api *API) ListPortProfiles(site string) ([]PortProfile, error) {
+	var resp []PortProfile
+	if err := api.get("/api/s/"+site+"/rest/portconf", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PortOverride is the configuration of a single switch port.
+// Fields that are the zero value leave that aspect of the port unchanged.
+type PortOverride struct {
+	PortIndex     int    `json:"port_idx"` // 1-based
+	Name          string `json:"name,omitempty"`
+	PortProfileID string `json:"portconf_id,omitempty"`
+	PoEMode       string `json:"poe_mode,omitempty"`
+	Isolation     *bool  `json:"isolation,omitempty"`
+
+	// TODO: other fields
+}
+
+// UpdateDevicePortOverrides sets the configuration of ports of the switch
+// with the given MAC address. Ports not mentioned are left unchanged,
+// as are any aspects of their configuration that PortOverride does not model
+// or that are not set.
+func (api *API) UpdateDevicePortOverrides(site, mac string, overrides []PortOverride) error {
+	// The port overrides must be written as a whole, so merge with what's there.
+	var dev struct {
+		ID            string                   `json:"_id"`
+		PortOverrides []map[string]interface{} `json:"port_overrides"`
+	}
+	if err := api.getDevice(context.Background(), site, mac, &dev); err != nil {
+		return err
+	}
+	for _, po := range overrides {
+		raw, err := json.Marshal(po)
+		if err != nil {
+			return err
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return err
+		}
+		found := false
+		for _, cur := range dev.PortOverrides {
+			if idx, _ := cur["port_idx"].(float64); int(idx) == po.PortIndex {
+				for k, v := range m {
+					cur[k] = v
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			dev.PortOverrides = append(dev.PortOverrides, m)
+		}
+	}
+	req := struct {
+		PortOverrides []map[string]interface{} `json:"port_overrides"`
+	}{dev.PortOverrides}
+	return api.updateDevice(site, dev.ID, &req)
+}
+
+// Port is the state of a single port of a device.
+type Port struct {
+	Index int    `json:"port_idx"` // 1-based
+	Name  string `json:"name"`
+	Up    bool   `json:"up"`
+	Speed int    `json:"speed"`    // Mbps
+	PoE   bool   `json:"port_poe"` // whether the port can supply PoE
+
+	// TODO: other fields
+}
+
+// PowerCyclePort briefly cuts PoE power to a port of the switch with the given MAC address,
+// rebooting whatever it powers.
+func (api *API) PowerCyclePort(site, mac string, port int) error {
+	var dev Device
+	if err := api.getDevice(context.Background(), site, mac, &dev); err != nil {
+		return err
+	}
+	var p *Port
+	for i := range dev.Ports {
+		if dev.Ports[i].Index == port {
+			p = &dev.Ports[i]
+			break
+		}
+	}
+	if p == nil {
+		return fmt.Errorf("device %s has no port %d (it has %d ports)", dev.MAC, port, len(dev.Ports))
+	}
+	if !p.PoE {
+		return fmt.Errorf("port %d of device %s does not support PoE", port, dev.MAC)
+	}
+	req := struct {
+		Cmd   string `json:"cmd"`
+		MAC   string `json:"mac"`
+		Index int    `json:"port_idx"`
+	}{"power-cycle", dev.MAC, port}
+	return api.post("/api/s/"+site+"/cmd/devmgr", &req, &json.RawMessage{}, reqOpts{})
+}
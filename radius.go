@@ -0,0 +1,62 @@
+package unifi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RADIUSProfile is a set of RADIUS servers, used by enterprise (802.1X) wireless networks.
+// See WirelessNetwork.
+type RADIUSProfile struct {
+	ID   string `json:"_id,omitempty"`
+	Name string `json:"name"`
+
+	AuthServers       []RADIUSServer `json:"auth_servers"`
+	AccountingEnabled bool           `json:"accounting_enabled"`
+	AcctServers       []RADIUSServer `json:"acct_servers"`
+
+	// TODO: other fields
+}
+
+// RADIUSServer is one server of a RADIUSProfile.
+type RADIUSServer struct {
+	IP   string `json:"ip"`
+	Port int    `json:"port"` // usually 1812 for authentication, 1813 for accounting
+
+	// Secret is the shared secret. Controllers may not report it,
+	// so it must be set again when updating a profile.
+	Secret string `json:"x_secret,omitempty"`
+}
+
+func (api *API) ListRADIUSProfiles(site string) ([]RADIUSProfile, error) {
+	var resp []RADIUSProfile
+	if err := api.get("/api/s/"+site+"/rest/radiusprofile", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CreateRADIUSProfile adds a RADIUS profile, returning it as created by the controller.
+func (api *API) CreateRADIUSProfile(site string, p RADIUSProfile) (RADIUSProfile, error) {
+	p.ID = ""
+	var resp []RADIUSProfile
+	if err := api.post("/api/s/"+site+"/rest/radiusprofile", p, &resp, reqOpts{}); err != nil {
+		return RADIUSProfile{}, err
+	}
+	if len(resp) == 0 {
+		return RADIUSProfile{}, fmt.Errorf("controller did not report the new RADIUS profile")
+	}
+	return resp[0], nil
+}
+
+// UpdateRADIUSProfile replaces the RADIUS profile with ID p.ID.
+func (api *API) UpdateRADIUSProfile(site string, p RADIUSProfile) error {
+	if p.ID == "" {
+		return fmt.Errorf("RADIUS profile has no ID")
+	}
+	return api.put("/api/s/"+site+"/rest/radiusprofile/"+p.ID, p, &json.RawMessage{}, reqOpts{})
+}
+
+func (api *API) DeleteRADIUSProfile(site, id string) error {
+	return api.del("/api/s/"+site+"/rest/radiusprofile/"+id, &json.RawMessage{}, reqOpts{})
+}
@@ -0,0 +1,138 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RogueAP is a neighbouring access point detected by one of the site's access points.
+type RogueAP struct {
+	ESSID    string `json:"essid"`
+	BSSID    string `json:"bssid"`
+	Radio    string `json:"radio"` // "ng", "na"
+	Channel  int    `json:"channel"`
+	Signal   int    `json:"signal"` // dBm
+	Security string `json:"security"`
+
+	APMAC string `json:"ap_mac"` // the access point that saw it
+
+	LastSeen time.Time
+
+	// TODO: other fields
+}
+
+func (r *RogueAP) UnmarshalJSON(data []byte) error {
+	type Alias RogueAP
+	aux := struct {
+		*Alias
+
+		LastSeen int64 `json:"last_seen"`
+	}{Alias: (*Alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.LastSeen = unixTime(aux.LastSeen)
+	return nil
+}
+
+// ListRogueAPs returns the neighbouring access points detected on a site.
+// See StartSpectrumScan for a way to refresh this data.
+func (api *API) ListRogueAPs(site string) ([]RogueAP, error) {
+	var resp []RogueAP
+	if err := api.get("/api/s/"+site+"/stat/rogueap", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RadioStat holds the current statistics of one radio of an access point.
+type RadioStat struct {
+	Band       string `json:"radio"` // "ng" (2.4 GHz) or "na" (5 GHz)
+	Channel    int    `json:"channel"`
+	NumClients int    `json:"num_sta"`
+
+	// Channel utilization, as percentages.
+	Utilization   int `json:"cu_total"`   // total
+	SelfRxPercent int `json:"cu_self_rx"` // receiving by this radio
+	SelfTxPercent int `json:"cu_self_tx"` // transmitting by this radio
+
+	// TODO: other fields
+}
+
+// APRadioStats returns the statistics of each radio of the access point with the given MAC address.
+func (api *API) APRadioStats(site, mac string) ([]RadioStat, error) {
+	var dev struct {
+		Stats []RadioStat `json:"radio_table_stats"`
+	}
+	if err := api.getDevice(context.Background(), site, mac, &dev); err != nil {
+		return nil, err
+	}
+	if dev.Stats == nil {
+		return []RadioStat{}, nil
+	}
+	return dev.Stats, nil
+}
+
+// RFScan starts an RF scan on every adopted access point of a site.
+// Clients lose service from each access point while it scans.
+// A scan that fails to start doesn't prevent the rest;
+// the returned error joins the failures for all access points.
+// See ChannelRecommendations for the results.
+func (api *API) RFScan(site string) error {
+	devs, err := api.ListDevices(site)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, dev := range devs {
+		if !dev.Adopted || dev.Type != "uap" {
+			continue
+		}
+		if err := api.StartSpectrumScan(site, dev.MAC); err != nil {
+			errs = append(errs, fmt.Errorf("device %s (%s): %w", dev.Name, dev.MAC, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ChannelRec is a recommended channel for one radio of an access point.
+type ChannelRec struct {
+	APMAC       string
+	Band        string // "ng" (2.4 GHz) or "na" (5 GHz)
+	Channel     int
+	Utilization int // percentage, as measured by the scan
+}
+
+// ChannelRecommendations returns, for each radio of each access point on a site
+// that has RF scan results, the channel that the most recent scan found least utilized.
+func (api *API) ChannelRecommendations(site string) ([]ChannelRec, error) {
+	var resp []struct {
+		MAC   string `json:"mac"`
+		Table []struct {
+			Band        string `json:"band"`
+			Channel     int    `json:"channel"`
+			Utilization int    `json:"utilization"`
+		} `json:"spectrum_table"`
+	}
+	if err := api.get("/api/s/"+site+"/stat/spectrumscan", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	recs := []ChannelRec{}
+	for _, ap := range resp {
+		best := make(map[string]int) // band => index into recs
+		for _, ch := range ap.Table {
+			rec := ChannelRec{APMAC: ap.MAC, Band: ch.Band, Channel: ch.Channel, Utilization: ch.Utilization}
+			i, ok := best[ch.Band]
+			if !ok {
+				best[ch.Band] = len(recs)
+				recs = append(recs, rec)
+			} else if ch.Utilization < recs[i].Utilization {
+				recs[i] = rec
+			}
+		}
+	}
+	return recs, nil
+}
@@ -0,0 +1,80 @@
+package unifi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StaticRoute is a static route configured on a site's gateway.
+type StaticRoute struct {
+	ID      string `json:"_id,omitempty"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+
+	RouteType string `json:"static-route_type"`    // "nexthop-route", "interface-route" or "blackhole"
+	Network   string `json:"static-route_network"` // destination, in CIDR notation
+	NextHop   string `json:"static-route_nexthop"`
+	Distance  int    `json:"-"`
+
+	// TODO: other fields
+}
+
+func (sr *StaticRoute) UnmarshalJSON(data []byte) error {
+	type Alias StaticRoute
+	aux := struct {
+		*Alias
+
+		Distance json.RawMessage `json:"static-route_distance"` // may be a string
+	}{Alias: (*Alias)(sr)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	sr.Distance = looseInt(aux.Distance)
+	return nil
+}
+
+func (sr StaticRoute) MarshalJSON() ([]byte, error) {
+	type Alias StaticRoute
+	return json.Marshal(struct {
+		Alias
+
+		Type     string `json:"type"`
+		Distance int    `json:"static-route_distance"`
+	}{
+		Alias:    Alias(sr),
+		Type:     "static-route",
+		Distance: sr.Distance,
+	})
+}
+
+func (api *API) ListStaticRoutes(site string) ([]StaticRoute, error) {
+	var resp []StaticRoute
+	if err := api.get("/api/s/"+site+"/rest/routing", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CreateStaticRoute adds a static route, returning it as created by the controller.
+// If r.RouteType is empty, "nexthop-route" is used, and if r.Distance is zero, 1 is used.
+func (api *API) CreateStaticRoute(site string, r StaticRoute) (StaticRoute, error) {
+	r.ID = ""
+	if r.RouteType == "" {
+		r.RouteType = "nexthop-route"
+	}
+	if r.Distance == 0 {
+		r.Distance = 1
+	}
+	var resp []StaticRoute
+	if err := api.post("/api/s/"+site+"/rest/routing", r, &resp, reqOpts{}); err != nil {
+		return StaticRoute{}, err
+	}
+	if len(resp) == 0 {
+		return StaticRoute{}, fmt.Errorf("controller did not report the new static route")
+	}
+	return resp[0], nil
+}
+
+func (api *API) DeleteStaticRoute(site, id string) error {
+	return api.del("/api/s/"+site+"/rest/routing/"+id, &json.RawMessage{}, reqOpts{})
+}
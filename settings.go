@@ -0,0 +1,204 @@
+package unifi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// getSetting fetches the site setting with the given key, decoding it into dst.
+func (api *API) getSetting(site, key string, dst interface{}) error {
+	var resp []json.RawMessage
+	if err := api.get("/api/s/"+site+"/rest/setting/"+key, &resp, reqOpts{}); err != nil {
+		return err
+	}
+	if len(resp) == 0 {
+		return ErrNotFound
+	}
+	return json.Unmarshal(resp[0], dst)
+}
+
+// updateSetting updates the site setting with the given key.
+// Fields not present in src are left unchanged.
+// If id is empty, the setting is fetched first to find it.
+func (api *API) updateSetting(site, key, id string, src interface{}) error {
+	if id == "" {
+		var cur struct {
+			ID string `json:"_id"`
+		}
+		if err := api.getSetting(site, key, &cur); err != nil {
+			return err
+		}
+		id = cur.ID
+	}
+	return api.put("/api/s/"+site+"/rest/setting/"+key+"/"+id, src, &json.RawMessage{}, reqOpts{})
+}
+
+// SiteSettings returns the raw site setting with the given key (e.g. "mgmt", "ntp", "locale").
+// It may be used for settings that this package does not otherwise support.
+func (api *API) SiteSettings(site, key string) (json.RawMessage, error) {
+	var raw json.RawMessage
+	if err := api.getSetting(site, key, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// NTPSettings holds the NTP settings of a site.
+type NTPSettings struct {
+	ID      string   `json:"-"`
+	Servers []string `json:"-"` // at most four
+}
+
+func (ns *NTPSettings) UnmarshalJSON(data []byte) error {
+	var aux map[string]interface{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	ns.ID, _ = aux["_id"].(string)
+	ns.Servers = nil
+	for i := 1; i <= 4; i++ {
+		if srv, _ := aux[fmt.Sprintf("ntp_server_%d", i)].(string); srv != "" {
+			ns.Servers = append(ns.Servers, srv)
+		}
+	}
+	return nil
+}
+
+func (ns NTPSettings) MarshalJSON() ([]byte, error) {
+	if len(ns.Servers) > 4 {
+		return nil, fmt.Errorf("too many NTP servers (%d > 4)", len(ns.Servers))
+	}
+	m := make(map[string]string)
+	for i := 1; i <= 4; i++ {
+		var srv string
+		if i <= len(ns.Servers) {
+			srv = ns.Servers[i-1]
+		}
+		m[fmt.Sprintf("ntp_server_%d", i)] = srv
+	}
+	return json.Marshal(m)
+}
+
+// NTPSettings returns the NTP settings of a site.
+func (api *API) NTPSettings(site string) (NTPSettings, error) {
+	var ns NTPSettings
+	if err := api.getSetting(site, "ntp", &ns); err != nil {
+		return NTPSettings{}, err
+	}
+	return ns, nil
+}
+
+// UpdateNTPSettings sets the NTP settings of a site.
+// It is an error to give more than four servers.
+func (api *API) UpdateNTPSettings(site string, ns NTPSettings) error {
+	if len(ns.Servers) > 4 {
+		return fmt.Errorf("too many NTP servers (%d > 4)", len(ns.Servers))
+	}
+	return api.updateSetting(site, "ntp", ns.ID, ns)
+}
+
+// LocaleSettings holds the locale settings of a site.
+type LocaleSettings struct {
+	ID       string `json:"_id,omitempty"`
+	TimeZone string `json:"timezone"` // e.g. "Australia/Sydney"
+}
+
+// LocaleSettings returns the locale settings of a site.
+func (api *API) LocaleSettings(site string) (LocaleSettings, error) {
+	var ls LocaleSettings
+	if err := api.getSetting(site, "locale", &ls); err != nil {
+		return LocaleSettings{}, err
+	}
+	return ls, nil
+}
+
+// UpdateLocaleSettings sets the locale settings of a site.
+func (api *API) UpdateLocaleSettings(site string, ls LocaleSettings) error {
+	return api.updateSetting(site, "locale", ls.ID, ls)
+}
+
+// GuestSettings holds the guest access (captive portal) settings of a site.
+type GuestSettings struct {
+	ID string `json:"_id,omitempty"`
+
+	PortalEnabled bool   `json:"portal_enabled"`
+	Auth          string `json:"auth"` // "none", "hotspot", "facebook_wifi" or "custom"
+
+	RedirectEnabled bool   `json:"redirect_enabled"`
+	RedirectURL     string `json:"redirect_url"`
+
+	Expire time.Duration `json:"-"` // how long guests stay authorized; at minute granularity
+
+	// TODO: other fields
+}
+
+func (gs *GuestSettings) UnmarshalJSON(data []byte) error {
+	type Alias GuestSettings
+	aux := struct {
+		*Alias
+
+		Expire json.RawMessage `json:"expire"` // minutes
+	}{Alias: (*Alias)(gs)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	gs.Expire = time.Duration(looseInt(aux.Expire)) * time.Minute
+	return nil
+}
+
+func (gs GuestSettings) MarshalJSON() ([]byte, error) {
+	type Alias GuestSettings
+	return json.Marshal(struct {
+		Alias
+
+		Expire int `json:"expire"` // minutes
+	}{
+		Alias:  Alias(gs),
+		Expire: int(gs.Expire / time.Minute),
+	})
+}
+
+// GuestSettings returns the guest access settings of a site.
+func (api *API) GuestSettings(site string) (GuestSettings, error) {
+	var gs GuestSettings
+	if err := api.getSetting(site, "guest_access", &gs); err != nil {
+		return GuestSettings{}, err
+	}
+	return gs, nil
+}
+
+// UpdateGuestSettings sets the guest access settings of a site.
+// It is typically used to modify the result of GuestSettings.
+func (api *API) UpdateGuestSettings(site string, gs GuestSettings) error {
+	return api.updateSetting(site, "guest_access", gs.ID, gs)
+}
+
+// AutoBackupConfig holds the controller's automatic backup settings.
+// These are part of the controller-wide settings, which are accessed through a site
+// (typically "default").
+type AutoBackupConfig struct {
+	ID string `json:"_id,omitempty"`
+
+	Enabled  bool   `json:"autobackup_enabled"`
+	CronExpr string `json:"autobackup_cron_expr"` // when to make backups, e.g. "0 0 * * *"
+	MaxFiles int    `json:"autobackup_max_files"` // how many backups to keep
+	Days     int    `json:"autobackup_days"`      // how many days of data to include; 0 means only settings
+
+	// TODO: other fields
+}
+
+// AutoBackupSettings returns the controller's automatic backup settings.
+func (api *API) AutoBackupSettings(site string) (AutoBackupConfig, error) {
+	var c AutoBackupConfig
+	if err := api.getSetting(site, "super_mgmt", &c); err != nil {
+		return AutoBackupConfig{}, err
+	}
+	return c, nil
+}
+
+// UpdateAutoBackupSettings sets the controller's automatic backup settings.
+// Other controller-wide settings are left unchanged.
+func (api *API) UpdateAutoBackupSettings(site string, c AutoBackupConfig) error {
+	return api.updateSetting(site, "super_mgmt", c.ID, c)
+}
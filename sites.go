@@ -0,0 +1,151 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Site is a site managed by the controller.
+type Site struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"` // the short name used in API calls, e.g. "default"
+	Desc string `json:"desc"` // the human-readable name
+
+	// Role is the current user's role on the site (e.g. "admin", "readonly").
+	// It is only reported when listing the current user's sites.
+	Role string `json:"role,omitempty"`
+
+	// TODO: other fields
+}
+
+// SiteConfig is a snapshot of the configuration of a site.
+// Parts of the configuration that this package does not model are kept as raw JSON,
+// which is suitable for serializing and comparing.
+type SiteConfig struct {
+	WirelessNetworks []WirelessNetwork
+	Networks         []json.RawMessage
+	PortForwards     []json.RawMessage
+	FirewallRules    []json.RawMessage
+	Settings         []json.RawMessage
+}
+
+// ExportSiteConfig returns a snapshot of the configuration of a site.
+func (api *API) ExportSiteConfig(site string) (SiteConfig, error) {
+	var sc SiteConfig
+	var err error
+	if sc.WirelessNetworks, err = api.ListWirelessNetworks(site); err != nil {
+		return SiteConfig{}, fmt.Errorf("exporting wireless networks: %w", err)
+	}
+	for _, part := range []struct {
+		what, path string
+		dst        *[]json.RawMessage
+	}{
+		{"networks", "list/networkconf", &sc.Networks},
+		{"port forwards", "list/portforward", &sc.PortForwards},
+		{"firewall rules", "list/firewallrule", &sc.FirewallRules},
+		{"settings", "get/setting", &sc.Settings},
+	} {
+		if err := api.get("/api/s/"+site+"/"+part.path, part.dst, reqOpts{}); err != nil {
+			return SiteConfig{}, fmt.Errorf("exporting %s: %w", part.what, err)
+		}
+	}
+	return sc, nil
+}
+
+// ListSites returns the sites that the current user can access.
+func (api *API) ListSites() ([]Site, error) {
+	var resp []Site
+	if err := api.get("/api/self/sites", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// sitemgr issues a site manager command in the context of the given site.
+func (api *API) sitemgr(site string, req, dst interface{}) error {
+	return api.post("/api/s/"+site+"/cmd/sitemgr", req, dst, reqOpts{})
+}
+
+// CreateSite creates a new site with the given description (human-readable name).
+// The controller chooses the site's short name.
+func (api *API) CreateSite(desc string) (Site, error) {
+	req := struct {
+		Cmd  string `json:"cmd"`
+		Desc string `json:"desc"`
+	}{"add-site", desc}
+	var resp []Site
+	if err := api.sitemgr("default", &req, &resp); err != nil {
+		return Site{}, err
+	}
+	if len(resp) == 0 {
+		return Site{}, fmt.Errorf("controller did not report the new site")
+	}
+	return resp[0], nil
+}
+
+// DeleteSite deletes the site with the given short name, and all its configuration.
+func (api *API) DeleteSite(name string) error {
+	sites, err := api.ListSites()
+	if err != nil {
+		return err
+	}
+	for _, s := range sites {
+		if s.Name != name {
+			continue
+		}
+		req := struct {
+			Cmd  string `json:"cmd"`
+			Site string `json:"site"` // ID
+		}{"delete-site", s.ID}
+		return api.sitemgr("default", &req, &json.RawMessage{})
+	}
+	return fmt.Errorf("site %q: %w", name, ErrNotFound)
+}
+
+// RenameSite changes the description (human-readable name) of the site with the given short name.
+func (api *API) RenameSite(name, newDesc string) error {
+	req := struct {
+		Cmd  string `json:"cmd"`
+		Desc string `json:"desc"`
+	}{"update-site", newDesc}
+	return api.sitemgr(name, &req, &json.RawMessage{})
+}
+
+// ForEachSite calls fn for each site that the current user can access,
+// with at most concurrency calls running at once.
+// The site's short name is passed to fn.
+// It returns the errors from all the calls, each annotated with its site,
+// so callers may use errors.Is (e.g. with ErrNoSiteAccess) to skip particular failures.
+// No further calls are started once ctx is done.
+func (api *API) ForEachSite(ctx context.Context, concurrency int, fn func(ctx context.Context, site string) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sites, err := api.ListSites()
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(sites))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, s := range sites {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = fmt.Errorf("site %q: %w", s.Name, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(i int, site string) {
+			defer func() { <-sem; wg.Done() }()
+			if err := fn(ctx, site); err != nil {
+				errs[i] = fmt.Errorf("site %q: %w", site, err)
+			}
+		}(i, s.Name)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
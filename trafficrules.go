@@ -0,0 +1,43 @@
+package unifi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TrafficRule is a rule that blocks or limits certain traffic,
+// such as to a category of applications.
+type TrafficRule struct {
+	ID             string `json:"_id"`
+	Description    string `json:"description"`
+	Enabled        bool   `json:"enabled"`
+	Action         string `json:"action"`          // "BLOCK" or "ALLOW"
+	MatchingTarget string `json:"matching_target"` // "APP", "DOMAIN", "INTERNET", etc.
+
+	// TODO: other fields
+}
+
+func (api *API) ListTrafficRules(site string) ([]TrafficRule, error) {
+	var resp []TrafficRule
+	if err := api.get("/v2/api/site/"+site+"/trafficrules", &resp, reqOpts{v2: true}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SetTrafficRuleEnabled enables or disables a traffic rule.
+func (api *API) SetTrafficRuleEnabled(site, id string, enable bool) error {
+	// Rules must be written as a whole, so preserve everything about it.
+	var rules []map[string]interface{}
+	if err := api.get("/v2/api/site/"+site+"/trafficrules", &rules, reqOpts{v2: true}); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule["_id"] != id {
+			continue
+		}
+		rule["enabled"] = enable
+		return api.put("/v2/api/site/"+site+"/trafficrules/"+id, rule, &json.RawMessage{}, reqOpts{v2: true})
+	}
+	return fmt.Errorf("traffic rule %s: %w", id, ErrNotFound)
+}
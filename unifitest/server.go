@@ -0,0 +1,168 @@
+/*
+Package unifitest provides a fake UniFi controller for testing code that uses package unifi.
+*/
+package unifitest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/dsymonds/unifi"
+)
+
+// Server is a fake UniFi controller.
+// It implements the controller's login and CSRF handshake,
+// and serves canned data for other endpoints.
+type Server struct {
+	*httptest.Server
+
+	// The credentials accepted by the server.
+	// These may be changed before a client logs in.
+	Username, Password string
+
+	mu       sync.Mutex
+	sessions map[string]string      // session cookie value => CSRF token
+	data     map[string]interface{} // keyed by path
+}
+
+// NewServer starts and returns a new Server.
+// It serves empty lists for the common endpoints of the "default" site;
+// use Handle to serve other data.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Username: "admin",
+		Password: "password",
+		sessions: make(map[string]string),
+		data:     make(map[string]interface{}),
+	}
+	s.Handle("/api/self", []interface{}{map[string]interface{}{"name": s.Username}})
+	s.Handle("/api/self/sites", []interface{}{map[string]interface{}{"name": "default", "desc": "Default", "role": "admin"}})
+	for _, p := range []string{"stat/sta", "stat/alluser", "stat/device", "stat/health", "stat/event", "list/wlanconf"} {
+		s.Handle("/api/s/default/"+p, []interface{}{})
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Handle sets the data served for requests to the given path, of any method.
+// data is JSON-encoded as the "data" field of the response.
+func (s *Server) Handle(path string, data interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[path] = data
+}
+
+// AuthStore returns an in-memory unifi.AuthStore holding the server's address and credentials.
+func (s *Server) AuthStore() unifi.AuthStore {
+	return &memStore{auth: unifi.Auth{
+		Username:       s.Username,
+		Password:       s.Password,
+		ControllerHost: strings.TrimPrefix(s.URL, "https://"),
+	}}
+}
+
+// API returns a new unifi.API that talks to the server.
+func (s *Server) API(opts *unifi.Options) (*unifi.API, error) {
+	return unifi.NewAPI(s.AuthStore(), opts)
+}
+
+const sessionCookie = "unifises"
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/login" {
+		s.login(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := r.Cookie(sessionCookie)
+	if err != nil || s.sessions[c.Value] == "" {
+		reply(w, http.StatusUnauthorized, "error", "api.err.LoginRequired", []interface{}{})
+		return
+	}
+	if r.Method != "GET" && r.Header.Get("X-CSRF-Token") != s.sessions[c.Value] {
+		reply(w, http.StatusForbidden, "error", "api.err.InvalidCSRFToken", []interface{}{})
+		return
+	}
+	data, ok := s.data[r.URL.Path]
+	if !ok {
+		reply(w, http.StatusNotFound, "error", "api.err.NotFound", []interface{}{})
+		return
+	}
+	reply(w, http.StatusOK, "ok", "", data)
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if r.Method != "POST" || json.NewDecoder(r.Body).Decode(&req) != nil {
+		reply(w, http.StatusBadRequest, "error", "api.err.Invalid", []interface{}{})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Username != s.Username || req.Password != s.Password {
+		reply(w, http.StatusBadRequest, "error", "api.err.Invalid", []interface{}{})
+		return
+	}
+	session, csrf := randToken(), randToken()
+	s.sessions[session] = csrf
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session, Path: "/"})
+	w.Header().Set("X-CSRF-Token", csrf)
+	reply(w, http.StatusOK, "ok", "", []interface{}{})
+}
+
+func reply(w http.ResponseWriter, status int, code, msg string, data interface{}) {
+	var resp struct {
+		Meta struct {
+			Code string `json:"rc"`
+			Msg  string `json:"msg,omitempty"`
+		} `json:"meta"`
+		Data interface{} `json:"data"`
+	}
+	resp.Meta.Code, resp.Meta.Msg = code, msg
+	resp.Data = data
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&resp)
+}
+
+func randToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("unifitest: reading random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// memStore is an in-memory unifi.AuthStore.
+type memStore struct {
+	mu   sync.Mutex
+	auth unifi.Auth
+}
+
+func (m *memStore) Load() (*unifi.Auth, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a := m.auth
+	return &a, nil
+}
+
+func (m *memStore) Save(auth *unifi.Auth) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.auth = *auth
+	return nil
+}
@@ -0,0 +1,235 @@
+package unifi
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// This is a minimal websocket (RFC 6455) client, sufficient for receiving
+// the controller's event stream. The handshake goes through the API's
+// http.Client, so it uses the same cookies, proxy and certificate checks
+// as other requests.
+
+// maxWSMessage bounds the size of a websocket message, to guard against
+// a misbehaving controller.
+const maxWSMessage = 16 << 20
+
+// wsPingInterval is how often a connection is pinged.
+// If nothing at all is received for three intervals, the connection is
+// assumed to be dead and is closed.
+const wsPingInterval = 30 * time.Second
+
+type wsConn struct {
+	rwc io.ReadWriteCloser
+	r   *bufio.Reader
+
+	wmu sync.Mutex // serializes writes
+
+	mu       sync.Mutex
+	lastRead time.Time
+	done     chan struct{} // closed by close
+	closed   bool
+}
+
+// dialWebsocket opens a websocket connection to a path on the controller.
+// The connection is closed when ctx is done.
+func (api *API) dialWebsocket(ctx context.Context, path string) (*wsConn, error) {
+	u, err := api.url(path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	api.setHeaders(req)
+	req.Header.Del("Accept-Encoding")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if err := api.wait(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := api.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		closeBody(resp.Body)
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	// For a 101 response, net/http gives access to the connection through the body.
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("websocket connection is not writable")
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != wsAccept(key) {
+		rwc.Close()
+		return nil, errors.New("bad websocket handshake from controller")
+	}
+
+	c := &wsConn{
+		rwc:      rwc,
+		r:        bufio.NewReader(rwc),
+		lastRead: time.Now(),
+		done:     make(chan struct{}),
+	}
+	go c.keepalive(ctx)
+	return c, nil
+}
+
+// wsAccept returns the Sec-WebSocket-Accept value expected for a Sec-WebSocket-Key.
+func wsAccept(key string) string {
+	sum := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// keepalive pings the connection periodically, closing it if it goes quiet
+// or when ctx is done.
+func (c *wsConn) keepalive(ctx context.Context) {
+	t := time.NewTicker(wsPingInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.close()
+			return
+		case <-c.done:
+			return
+		case <-t.C:
+		}
+		c.mu.Lock()
+		quiet := time.Since(c.lastRead)
+		c.mu.Unlock()
+		if quiet > 3*wsPingInterval || c.writeFrame(wsPing, nil) != nil {
+			c.close()
+			return
+		}
+	}
+}
+
+func (c *wsConn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.done)
+	c.rwc.Close()
+}
+
+// Websocket opcodes.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+// readMessage returns the payload of the next data message,
+// answering any control frames that precede it.
+// It returns io.EOF if the controller closes the connection.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		var h [2]byte
+		if _, err := io.ReadFull(c.r, h[:]); err != nil {
+			return nil, err
+		}
+		fin, op := h[0]&0x80 != 0, h[0]&0x0F
+		n := uint64(h[1] & 0x7F)
+		switch n {
+		case 126:
+			var ext [2]byte
+			if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+				return nil, err
+			}
+			n = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+				return nil, err
+			}
+			n = binary.BigEndian.Uint64(ext[:])
+		}
+		if n > maxWSMessage || uint64(len(msg))+n > maxWSMessage {
+			return nil, fmt.Errorf("websocket message too large")
+		}
+		var mask [4]byte
+		masked := h[1]&0x80 != 0
+		if masked {
+			if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+				return nil, err
+			}
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(c.r, payload); err != nil {
+			return nil, err
+		}
+		if masked {
+			for i := range payload {
+				payload[i] ^= mask[i%4]
+			}
+		}
+		c.mu.Lock()
+		c.lastRead = time.Now()
+		c.mu.Unlock()
+
+		switch op {
+		case wsClose:
+			c.writeFrame(wsClose, nil) // best effort
+			return nil, io.EOF
+		case wsPing:
+			if err := c.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+		case wsPong:
+		case wsContinuation, wsText, wsBinary:
+			msg = append(msg, payload...)
+			if fin {
+				return msg, nil
+			}
+		default:
+			return nil, fmt.Errorf("unknown websocket opcode %#x", op)
+		}
+	}
+}
+
+// writeFrame writes a single frame, which must be small, masked as clients must.
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	if len(payload) > 125 {
+		return fmt.Errorf("websocket frame too large")
+	}
+	frame := []byte{0x80 | op, 0x80 | byte(len(payload)), 0, 0, 0, 0}
+	if _, err := rand.Read(frame[2:6]); err != nil {
+		return err
+	}
+	for i, b := range payload {
+		frame = append(frame, b^frame[2+i%4])
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.rwc.Write(frame)
+	return err
+}
@@ -0,0 +1,311 @@
+package unifi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ScheduleBlock is a period of a day during which a wireless network is enabled.
+type ScheduleBlock struct {
+	Day        time.Weekday
+	Start, End time.Duration // offsets from midnight; at minute granularity
+}
+
+func (b ScheduleBlock) validate() error {
+	if b.Day < time.Sunday || b.Day > time.Saturday {
+		return fmt.Errorf("bad schedule day %d", b.Day)
+	}
+	if b.Start < 0 || b.End > 24*time.Hour || b.Start >= b.End {
+		return fmt.Errorf("bad schedule period %v-%v on %v", b.Start, b.End, b.Day)
+	}
+	if b.Start%time.Minute != 0 || b.End%time.Minute != 0 {
+		return fmt.Errorf("schedule period %v-%v on %v is not at minute granularity", b.Start, b.End, b.Day)
+	}
+	return nil
+}
+
+func (b ScheduleBlock) day() string {
+	return strings.ToLower(b.Day.String()[:3])
+}
+
+// hhmm formats an offset from midnight in the controller's schedule format.
+func hhmm(d time.Duration) string {
+	return fmt.Sprintf("%02d%02d", d/time.Hour, d%time.Hour/time.Minute)
+}
+
+// SetWLANSchedule sets the schedule for a wireless network,
+// during which the controller will enable it.
+// An empty schedule disables scheduling, leaving the network always on (if enabled).
+func (api *API) SetWLANSchedule(site, id string, schedule []ScheduleBlock) error {
+	type durationBlock struct {
+		Days     []string `json:"start_days_of_week"`
+		Hour     int      `json:"start_hour"`
+		Minute   int      `json:"start_minute"`
+		Duration int      `json:"duration_minutes"`
+	}
+	req := struct {
+		Enabled      bool            `json:"schedule_enabled"`
+		Schedule     []string        `json:"schedule"`
+		WithDuration []durationBlock `json:"schedule_with_duration"`
+	}{
+		Enabled:      len(schedule) > 0,
+		Schedule:     []string{},
+		WithDuration: []durationBlock{},
+	}
+	for _, b := range schedule {
+		if err := b.validate(); err != nil {
+			return err
+		}
+		req.Schedule = append(req.Schedule, b.day()+"|"+hhmm(b.Start)+"-"+hhmm(b.End))
+		req.WithDuration = append(req.WithDuration, durationBlock{
+			Days:     []string{b.day()},
+			Hour:     int(b.Start / time.Hour),
+			Minute:   int(b.Start % time.Hour / time.Minute),
+			Duration: int((b.End - b.Start) / time.Minute),
+		})
+	}
+	return api.post("/api/s/"+site+"/upd/wlanconf/"+id, &req, &json.RawMessage{}, reqOpts{})
+}
+
+// GetWirelessNetwork returns the wireless network with the given name,
+// or ErrNotFound if there is none.
+// If several networks have the same name, the first one reported by the controller is returned.
+func (api *API) GetWirelessNetwork(site, name string) (*WirelessNetwork, error) {
+	wlans, err := api.ListWirelessNetworks(site)
+	if err != nil {
+		return nil, err
+	}
+	for i := range wlans {
+		if wlans[i].Name == name {
+			return &wlans[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// WLANGroup is a group of access points that broadcast the same wireless networks.
+// See WirelessNetwork.WLANGroupID.
+type WLANGroup struct {
+	ID      string `json:"_id"`
+	Name    string `json:"name"`
+	Default bool   `json:"attr_no_delete"` // the group of all access points
+
+	// TODO: other fields
+}
+
+func (api *API) ListWLANGroups(site string) ([]WLANGroup, error) {
+	var resp []WLANGroup
+	if err := api.get("/api/s/"+site+"/list/wlangroup", &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// APGroup is a group of access points, used by newer controllers
+// instead of WLANGroup. See WirelessNetwork.APGroupIDs.
+type APGroup struct {
+	ID      string   `json:"_id"`
+	Name    string   `json:"name"`
+	MACs    []string `json:"device_macs"`
+	Default bool     `json:"attr_no_delete"` // the group of all access points
+
+	// TODO: other fields
+}
+
+// ListAPGroups returns the access point groups of a site.
+// Controllers that predate them respond with a 404, so the error wraps
+// an HTTPError with that status.
+func (api *API) ListAPGroups(site string) ([]APGroup, error) {
+	var resp []APGroup
+	if err := api.get("/v2/api/site/"+site+"/apgroups", &resp, reqOpts{v2: true}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RotateWLANPassphrase changes the passphrase of a WPA-PSK wireless network.
+// The network's full configuration is read and written back with only the
+// passphrase changed, so no other settings are disturbed.
+func (api *API) RotateWLANPassphrase(site, id, newPassphrase string) error {
+	if n := len(newPassphrase); n < 8 || n > 63 {
+		return fmt.Errorf("passphrase must be 8 to 63 characters long, not %d", n)
+	}
+	var resp []map[string]interface{}
+	if err := api.get("/api/s/"+site+"/rest/wlanconf/"+id, &resp, reqOpts{}); err != nil {
+		return err
+	}
+	if len(resp) == 0 {
+		return ErrNotFound
+	}
+	conf := resp[0]
+	if conf["security"] != "wpapsk" {
+		return fmt.Errorf("wireless network %v does not use a passphrase (security is %v)", conf["name"], conf["security"])
+	}
+	conf["x_passphrase"] = newPassphrase
+	return api.put("/api/s/"+site+"/rest/wlanconf/"+id, conf, &json.RawMessage{}, reqOpts{})
+}
+
+// SetWLANMACFilter sets the MAC address filter of a wireless network.
+// policy is "allow" (only the listed clients may connect) or "deny"
+// (the listed clients may not connect). An empty policy disables filtering;
+// macs is then ignored, and the stored list is kept for when filtering is re-enabled.
+func (api *API) SetWLANMACFilter(site, id string, policy string, macs []string) error {
+	switch policy {
+	case "":
+		req := struct {
+			Enabled bool `json:"mac_filter_enabled"`
+		}{false}
+		return api.post("/api/s/"+site+"/upd/wlanconf/"+id, &req, &json.RawMessage{}, reqOpts{})
+	case "allow", "deny":
+	default:
+		return fmt.Errorf("unknown MAC filter policy %q", policy)
+	}
+	req := struct {
+		Enabled bool     `json:"mac_filter_enabled"`
+		Policy  string   `json:"mac_filter_policy"`
+		List    []string `json:"mac_filter_list"`
+	}{
+		Enabled: true,
+		Policy:  policy,
+		List:    []string{},
+	}
+	for _, mac := range macs {
+		mac, err := normalizeMAC(mac)
+		if err != nil {
+			return err
+		}
+		req.List = append(req.List, mac)
+	}
+	return api.post("/api/s/"+site+"/upd/wlanconf/"+id, &req, &json.RawMessage{}, reqOpts{})
+}
+
+// UpdateWirelessNetwork writes back the configuration of a wireless network,
+// as obtained from ListWirelessNetworks or GetWirelessNetwork and then modified.
+// Fields that WirelessNetwork doesn't model are preserved from w.Raw.
+func (api *API) UpdateWirelessNetwork(site string, w *WirelessNetwork) error {
+	conf, err := wlanConf(w)
+	if err != nil {
+		return err
+	}
+	return api.put("/api/s/"+site+"/rest/wlanconf/"+w.ID, conf, &json.RawMessage{}, reqOpts{})
+}
+
+// CreateWirelessNetwork adds a wireless network, returning it as created by the controller.
+// Any w.ID is ignored. Fields that WirelessNetwork doesn't model may be set in w.Raw.
+//
+// If w.WLANGroupID or w.APGroupIDs is unset, the site's default group
+// (of all access points) is used, if the controller has one,
+// so that the network is broadcast everywhere. To broadcast it only on
+// some access points, set the group IDs from ListWLANGroups or ListAPGroups.
+//
+// Other string and list fields that are unset are left to the controller's defaults.
+// Note that the network is created disabled unless w.Enabled is set.
+func (api *API) CreateWirelessNetwork(site string, w WirelessNetwork) (*WirelessNetwork, error) {
+	if w.WLANGroupID == "" {
+		groups, err := api.ListWLANGroups(site)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range groups {
+			if g.Default {
+				w.WLANGroupID = g.ID
+				break
+			}
+		}
+	}
+	if len(w.APGroupIDs) == 0 {
+		// Controllers without AP groups respond with a 404,
+		// which may be an HTML page rather than JSON.
+		groups, err := api.ListAPGroups(site)
+		var herr *HTTPError
+		if err != nil && !(errors.As(err, &herr) && herr.StatusCode == http.StatusNotFound) {
+			return nil, err
+		}
+		for _, g := range groups {
+			if g.Default {
+				w.APGroupIDs = []string{g.ID}
+				break
+			}
+		}
+	}
+	conf, err := wlanConf(&w)
+	if err != nil {
+		return nil, err
+	}
+	delete(conf, "_id")
+	// The controller rejects empty values for these, rather than using its defaults.
+	for _, k := range []string{"wpa_mode", "wlangroup_id", "mac_filter_policy", "mac_filter_list", "bandsteering_mode"} {
+		if v := conf[k]; v == nil || v == "" {
+			delete(conf, k)
+		}
+	}
+	var resp []WirelessNetwork
+	if err := api.post("/api/s/"+site+"/rest/wlanconf", conf, &resp, reqOpts{}); err != nil {
+		return nil, err
+	}
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("controller did not report the new wireless network")
+	}
+	return &resp[0], nil
+}
+
+// wlanConf returns the full configuration of w to send to the controller,
+// combining its modeled fields with those only in w.Raw.
+func wlanConf(w *WirelessNetwork) (map[string]interface{}, error) {
+	if w.Security == "wpaeap" && w.RADIUSProfileID == "" {
+		return nil, fmt.Errorf("wireless network %q uses enterprise security, but has no RADIUS profile", w.Name)
+	}
+	conf := make(map[string]interface{})
+	if len(w.Raw) > 0 {
+		if err := json.Unmarshal(w.Raw, &conf); err != nil {
+			return nil, fmt.Errorf("parsing raw wireless network: %w", err)
+		}
+	}
+	b, err := json.Marshal(w)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &conf); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// FieldChange is a difference in one field between two values of a struct.
+type FieldChange struct {
+	Field    string // the Go field name
+	Old, New interface{}
+}
+
+// Diff returns the fields of w that differ in other, in struct order.
+// The ID and Raw fields are not compared. A change to Passphrase is reported
+// with both values replaced by "[redacted]", so that changes may be logged.
+func (w WirelessNetwork) Diff(other WirelessNetwork) []FieldChange {
+	var changes []FieldChange
+	a, b := reflect.ValueOf(w), reflect.ValueOf(other)
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if name == "ID" || name == "Raw" {
+			continue
+		}
+		fa, fb := a.Field(i), b.Field(i)
+		if fa.Kind() == reflect.Slice && fa.Len() == 0 && fb.Len() == 0 {
+			continue // nil and empty are equivalent
+		}
+		if reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			continue
+		}
+		if name == "Passphrase" {
+			changes = append(changes, FieldChange{Field: name, Old: "[redacted]", New: "[redacted]"})
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Old: fa.Interface(), New: fb.Interface()})
+	}
+	return changes
+}
@@ -0,0 +1,118 @@
+package unifi_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/dsymonds/unifi"
+)
+
+// TestCreateWirelessNetworkWithoutAPGroups checks that creating a wireless network
+// works with controllers that predate AP groups, and so serve an HTML 404 for them.
+func TestCreateWirelessNetworkWithoutAPGroups(t *testing.T) {
+	var created map[string]interface{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/login":
+		case "/api/s/default/list/wlangroup":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"meta":{"rc":"ok"},"data":[{"_id":"g1","name":"Default","attr_no_delete":true}]}`)
+			return
+		case "/api/s/default/rest/wlanconf":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("Bad wlanconf body: %v", err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"meta":{"rc":"ok"},"data":[{"_id":"w1","name":"x"}]}`)
+			return
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<html>Not found</html>")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"meta":{"rc":"ok"},"data":[]}`)
+	}))
+	defer srv.Close()
+
+	api := newAPI(t, srv)
+	wn, err := api.CreateWirelessNetwork("default", unifi.WirelessNetwork{Name: "x", Security: "open"})
+	if err != nil {
+		t.Fatalf("CreateWirelessNetwork: %v", err)
+	}
+	if wn.ID != "w1" {
+		t.Errorf("CreateWirelessNetwork returned ID %q, want w1", wn.ID)
+	}
+	if created["wlangroup_id"] != "g1" {
+		t.Errorf("Created network has wlangroup_id %v, want g1", created["wlangroup_id"])
+	}
+	if _, ok := created["ap_group_ids"]; ok {
+		t.Errorf("Created network has ap_group_ids %v, want none", created["ap_group_ids"])
+	}
+}
+
+// TestCreateWirelessNetworkBody checks that a minimal wireless network
+// is created without empty values that the controller would reject.
+func TestCreateWirelessNetworkBody(t *testing.T) {
+	var created map[string]interface{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/s/default/rest/wlanconf":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("Bad wlanconf body: %v", err)
+			}
+			io.WriteString(w, `{"meta":{"rc":"ok"},"data":[{"_id":"w1","name":"x"}]}`)
+		case "/v2/api/site/default/apgroups":
+			io.WriteString(w, `[]`)
+		default:
+			io.WriteString(w, `{"meta":{"rc":"ok"},"data":[]}`)
+		}
+	}))
+	defer srv.Close()
+
+	api := newAPI(t, srv)
+	_, err := api.CreateWirelessNetwork("default", unifi.WirelessNetwork{
+		Name:       "x",
+		Enabled:    true,
+		Security:   "wpapsk",
+		Passphrase: "secret123",
+	})
+	if err != nil {
+		t.Fatalf("CreateWirelessNetwork: %v", err)
+	}
+	want := map[string]interface{}{
+		"name":         "x",
+		"enabled":      true,
+		"security":     "wpapsk",
+		"x_passphrase": "secret123",
+	}
+	for k, v := range want {
+		if created[k] != v {
+			t.Errorf("Created network has %s = %v, want %v", k, created[k], v)
+		}
+	}
+	for k, v := range created {
+		if v == nil || v == "" {
+			t.Errorf("Created network has empty %s = %#v", k, v)
+		}
+	}
+}
+
+func TestWirelessNetworkDiff(t *testing.T) {
+	a := unifi.WirelessNetwork{Name: "x", Passphrase: "old secret", MACFilterList: []string{}}
+	b := unifi.WirelessNetwork{Name: "y", Passphrase: "new secret"}
+	got := a.Diff(b)
+	want := []unifi.FieldChange{
+		{Field: "Name", Old: "x", New: "y"},
+		{Field: "Passphrase", Old: "[redacted]", New: "[redacted]"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff = %+v, want %+v", got, want)
+	}
+}